@@ -24,6 +24,7 @@ type API struct {
 	redirectURL               *url.URL
 	skipSSLValidation         bool
 	verbose                   bool
+	pageConcurrency           int
 	zoneID                    string
 	userAgent                 string
 	token                     *oauth2.Token
@@ -39,6 +40,8 @@ type API struct {
 	clientCredentialsConfig   *cc.Config
 	passwordCredentialsConfig *pc.Config
 	oauthConfig               *oauth2.Config
+	openIDDiscovery           bool
+	openIDConfigCache         *openIDConfigCache
 }
 
 // TokenFormat is the format of a token.
@@ -154,6 +157,9 @@ func (a *API) configure() error {
 	if err != nil {
 		return err
 	}
+	if a.openIDConfigCache == nil {
+		a.openIDConfigCache = &openIDConfigCache{}
+	}
 	if a.baseClient == nil {
 		return errors.New("please ensure you pass a non-nil client to uaa.WithClient, or remove the uaa.WithClient option")
 	}
@@ -170,13 +176,16 @@ func (a *API) configure() error {
 		LoggingEnabled: a.verbose,
 	}
 	a.baseClient.Transport = wrappedTransport
+	// Configuring authentication can fetch the OpenID discovery document, so
+	// the base client needs its timeout first.
+	a.ensureTimeout()
 	switch a.mode {
 	case token:
 		err = a.configureToken()
 	case clientcredentials:
-		a.configureClientCredentials()
+		err = a.configureClientCredentials()
 	case passwordcredentials:
-		a.configurePasswordCredentials()
+		err = a.configurePasswordCredentials()
 	case authorizationcode:
 		err = a.configureAuthorizationCode()
 	case refreshtoken:
@@ -194,7 +203,10 @@ func (a *API) configure() error {
 	if a.Client == nil {
 		return errors.New("Client is nil; please ensure you pass an AuthenticationOption (e.g. WithClientCredentials, WithPasswordCredentials, WithAuthorizationCode, WithRefreshToken, WithToken) to New(), or manually set Client")
 	}
+	// The clients' transports and timeouts are configured once, here, so
+	// that requests never modify state they share with other requests.
 	a.ensureTransport(a.Client.Transport)
+	a.ensureTimeout()
 	return nil
 }
 
@@ -273,6 +285,51 @@ func (w *withZoneID) Apply(a *API) {
 	a.zoneID = w.zoneID
 }
 
+// ForZone returns a copy of the API that sends requests to the zone with the
+// given ID using the X-Identity-Zone-Id header. The copy shares the parent's
+// HTTP client, and so its transport and token.
+func (a *API) ForZone(zoneID string) *API {
+	zoned := *a
+	zoned.zoneID = zoneID
+	zoned.openIDConfigCache = &openIDConfigCache{}
+	return &zoned
+}
+
+// ForZoneSubdomain returns a copy of the API that sends requests to the zone
+// with the given subdomain, by prefixing the subdomain to the target host as
+// BuildSubdomainURL does. The copy shares the parent's HTTP client, and so
+// its transport and token.
+func (a *API) ForZoneSubdomain(subdomain string) (*API, error) {
+	if a.TargetURL == nil {
+		return nil, errors.New("the target is missing")
+	}
+	u, err := BuildSubdomainURL(a.TargetURL.String(), subdomain)
+	if err != nil {
+		return nil, err
+	}
+	zoned := *a
+	zoned.TargetURL = u
+	zoned.target = u.String()
+	zoned.zoneID = ""
+	zoned.openIDConfigCache = &openIDConfigCache{}
+	return &zoned, nil
+}
+
+type withOpenIDDiscovery struct{}
+
+// WithOpenIDDiscovery makes the API locate the token, userinfo, JWKS and
+// introspection endpoints from the target's OpenID configuration, rather
+// than at their default paths on the target. The configuration is retrieved
+// when it is first needed, which for the authentication options that obtain
+// tokens is when the API is created.
+func WithOpenIDDiscovery() Option {
+	return &withOpenIDDiscovery{}
+}
+
+func (w *withOpenIDDiscovery) Apply(a *API) {
+	a.openIDDiscovery = true
+}
+
 type withVerbosity struct {
 	verbose bool
 }
@@ -285,6 +342,21 @@ func (w *withVerbosity) Apply(a *API) {
 	a.verbose = w.verbose
 }
 
+type withPageConcurrency struct {
+	concurrency int
+}
+
+// WithPageConcurrency enables fetching the pages of ListAll* calls in
+// parallel, using at most concurrency requests at a time. A concurrency of 1
+// or less fetches pages sequentially.
+func WithPageConcurrency(concurrency int) Option {
+	return &withPageConcurrency{concurrency: concurrency}
+}
+
+func (w *withPageConcurrency) Apply(a *API) {
+	a.pageConcurrency = w.concurrency
+}
+
 type withClientCredentials struct {
 	clientID     string
 	clientSecret string
@@ -302,8 +374,11 @@ func (w *withClientCredentials) ApplyAuthentication(a *API) {
 	a.tokenFormat = w.tokenFormat
 }
 
-func (a *API) configureClientCredentials() {
-	tokenURL := urlWithPath(*a.TargetURL, "/oauth/token")
+func (a *API) configureClientCredentials() error {
+	tokenURL, err := a.endpointURL(tokenEndpointPath)
+	if err != nil {
+		return err
+	}
 	v := url.Values{}
 	v.Add("token_format", a.tokenFormat.String())
 	c := &cc.Config{
@@ -319,6 +394,7 @@ func (a *API) configureClientCredentials() {
 		oauth2.HTTPClient,
 		a.baseClient,
 	))
+	return nil
 }
 
 type withPasswordCredentials struct {
@@ -348,8 +424,11 @@ func (w *withPasswordCredentials) ApplyAuthentication(a *API) {
 	a.tokenFormat = w.tokenFormat
 }
 
-func (a *API) configurePasswordCredentials() {
-	tokenURL := urlWithPath(*a.TargetURL, "/oauth/token")
+func (a *API) configurePasswordCredentials() error {
+	tokenURL, err := a.endpointURL(tokenEndpointPath)
+	if err != nil {
+		return err
+	}
 	v := url.Values{}
 	v.Add("token_format", a.tokenFormat.String())
 	c := &pc.Config{
@@ -367,6 +446,7 @@ func (a *API) configurePasswordCredentials() {
 		context.Background(),
 		oauth2.HTTPClient,
 		a.baseClient))
+	return nil
 }
 
 type withAuthorizationCode struct {
@@ -397,7 +477,10 @@ func (w *withAuthorizationCode) ApplyAuthentication(a *API) {
 }
 
 func (a *API) configureAuthorizationCode() error {
-	tokenURL := urlWithPath(*a.TargetURL, "/oauth/token")
+	tokenURL, err := a.endpointURL(tokenEndpointPath)
+	if err != nil {
+		return err
+	}
 	c := &oauth2.Config{
 		ClientID:     a.clientID,
 		ClientSecret: a.clientSecret,
@@ -447,7 +530,10 @@ func (w *withRefreshToken) ApplyAuthentication(a *API) {
 }
 
 func (a *API) configureRefreshToken() error {
-	tokenURL := urlWithPath(*a.TargetURL, "/oauth/token")
+	tokenURL, err := a.endpointURL(tokenEndpointPath)
+	if err != nil {
+		return err
+	}
 	query := tokenURL.Query()
 	query.Set("token_format", a.tokenFormat.String())
 	tokenURL.RawQuery = query.Encode()
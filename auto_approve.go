@@ -0,0 +1,145 @@
+package uaa
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type autoApproveForm int
+
+const (
+	autoApproveFormNone autoApproveForm = iota
+	autoApproveFormBool
+	autoApproveFormString
+	autoApproveFormArray
+)
+
+// AutoApprove is a client's autoapprove setting. UAA represents it as either
+// true (approve every scope), the string "true", a single scope, a
+// comma-separated list of scopes, or an array of scopes. AutoApprove decodes
+// all of these and encodes back to the form it was decoded from, so that a
+// client read from UAA can be updated without changing the setting.
+type AutoApprove struct {
+	// All is true when every scope is auto-approved.
+	All bool
+	// Scopes are the auto-approved scopes when All is false.
+	Scopes []string
+
+	form autoApproveForm
+}
+
+// AutoApproveAll returns an AutoApprove that approves every scope.
+func AutoApproveAll() AutoApprove {
+	return AutoApprove{All: true}
+}
+
+// AutoApproveScopes returns an AutoApprove that approves the given scopes.
+func AutoApproveScopes(scopes ...string) AutoApprove {
+	return AutoApprove{Scopes: scopes}
+}
+
+// IsZero reports whether the setting is unset, so that it is omitted when
+// the client is encoded.
+func (a AutoApprove) IsZero() bool {
+	return a.form == autoApproveFormNone && !a.All && len(a.Scopes) == 0
+}
+
+// Approves reports whether the given scope is auto-approved. As in UAA, a
+// scope list containing "true" approves every scope.
+func (a AutoApprove) Approves(scope string) bool {
+	return a.All || contains(a.Scopes, scope) || contains(a.Scopes, "true")
+}
+
+// Strings returns the setting as strings: "true" or "false" when it is
+// encoded as a boolean, otherwise the auto-approved scopes, or "true" when
+// every scope is approved.
+func (a AutoApprove) Strings() []string {
+	switch v := a.value().(type) {
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case string:
+		return splitScopes(v)
+	default:
+		return v.([]string)
+	}
+}
+
+// MarshalJSON encodes the setting in the form it was decoded from. A setting
+// built in code is encoded as true when All is set, and as an array of
+// scopes otherwise.
+func (a AutoApprove) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.value())
+}
+
+// value returns the setting as the value it is encoded as: a bool, a string
+// or a []string.
+func (a AutoApprove) value() interface{} {
+	switch a.form {
+	case autoApproveFormBool:
+		if len(a.Scopes) == 0 {
+			return a.All
+		}
+	case autoApproveFormString:
+		if a.All {
+			return "true"
+		}
+		return strings.Join(a.Scopes, ",")
+	}
+	if a.All {
+		return true
+	}
+	if a.Scopes == nil {
+		return []string{}
+	}
+	return a.Scopes
+}
+
+// splitScopes splits a comma-separated autoapprove string into scopes.
+func splitScopes(s string) []string {
+	scopes := []string{}
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// UnmarshalJSON decodes a boolean, string or array autoapprove value.
+func (a *AutoApprove) UnmarshalJSON(data []byte) error {
+	*a = AutoApprove{}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case nil:
+	case bool:
+		a.form = autoApproveFormBool
+		a.All = t
+	case string:
+		a.form = autoApproveFormString
+		if t == "true" {
+			a.All = true
+			break
+		}
+		if scopes := splitScopes(t); len(scopes) > 0 {
+			a.Scopes = scopes
+		}
+	case []interface{}:
+		a.form = autoApproveFormArray
+		a.Scopes = make([]string, 0, len(t))
+		for _, item := range t {
+			scope, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("autoapprove array must contain only strings, found %v", item)
+			}
+			a.Scopes = append(a.Scopes, scope)
+		}
+	default:
+		return fmt.Errorf("autoapprove must be a boolean, string or array of strings, found %s", data)
+	}
+	return nil
+}
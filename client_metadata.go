@@ -0,0 +1,66 @@
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ClientMetadata is the UI metadata for a UAA client, used to render the
+// client on the UAA home page
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#client-metadata.
+type ClientMetadata struct {
+	ClientID       string `json:"clientId,omitempty"`
+	ClientName     string `json:"clientName,omitempty"`
+	IdentityZoneID string `json:"identityZoneId,omitempty"`
+	ShowOnHomePage bool   `json:"showOnHomePage"`
+	AppLaunchURL   string `json:"appLaunchUrl,omitempty"`
+	AppIcon        string `json:"appIcon,omitempty"`
+	CreatedBy      string `json:"createdBy,omitempty"`
+}
+
+// GetClientMetadata gets the metadata for the client with the given ID.
+func (a *API) GetClientMetadata(clientID string) (*ClientMetadata, error) {
+	if clientID == "" {
+		return nil, errors.New("clientID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/meta", ClientsEndpoint, clientID))
+	metadata := &ClientMetadata{}
+	err := a.doJSON(http.MethodGet, &u, nil, metadata, true)
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// UpdateClientMetadata updates the metadata for the client identified by
+// metadata.ClientID.
+func (a *API) UpdateClientMetadata(metadata ClientMetadata) (*ClientMetadata, error) {
+	if metadata.ClientID == "" {
+		return nil, errorMissingValue("clientId")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/meta", ClientsEndpoint, metadata.ClientID))
+	j, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	updated := &ClientMetadata{}
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "0"}, bytes.NewBuffer([]byte(j)), updated, true)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListClientMetadata lists the metadata for all clients in the zone.
+func (a *API) ListClientMetadata() ([]ClientMetadata, error) {
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/meta", ClientsEndpoint))
+	var metadata []ClientMetadata
+	err := a.doJSON(http.MethodGet, &u, nil, &metadata, true)
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -0,0 +1,127 @@
+package uaa_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+const clientMetadataResponse string = `{
+	"clientId" : "myclient",
+	"clientName" : "My Client",
+	"identityZoneId" : "uaa",
+	"showOnHomePage" : true,
+	"appLaunchUrl" : "http://myloginpage.com",
+	"appIcon" : "iVBORw0KGgo="
+}`
+
+func testClientMetadata(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("GetClientMetadata()", func() {
+		it("gets the metadata for the client", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/myclient/meta"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(clientMetadataResponse))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			metadata, err := a.GetClientMetadata("myclient")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata).To(Equal(&uaa.ClientMetadata{
+				ClientID:       "myclient",
+				ClientName:     "My Client",
+				IdentityZoneID: "uaa",
+				ShowOnHomePage: true,
+				AppLaunchURL:   "http://myloginpage.com",
+				AppIcon:        "iVBORw0KGgo=",
+			}))
+		})
+
+		it("returns an error when the clientID is empty", func() {
+			_, err := a.GetClientMetadata("")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("returns an error when the request fails", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+			metadata, err := a.GetClientMetadata("myclient")
+			Expect(err).To(HaveOccurred())
+			Expect(metadata).To(BeNil())
+		})
+	})
+
+	when("UpdateClientMetadata()", func() {
+		it("performs a PUT with the metadata", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/myclient/meta"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"clientId":"myclient","showOnHomePage":false,"appLaunchUrl":"http://myloginpage.com"}`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(clientMetadataResponse))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			updated, err := a.UpdateClientMetadata(uaa.ClientMetadata{ClientID: "myclient", AppLaunchURL: "http://myloginpage.com"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.ClientName).To(Equal("My Client"))
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns an error when the clientId is empty", func() {
+			_, err := a.UpdateClientMetadata(uaa.ClientMetadata{})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("ListClientMetadata()", func() {
+		it("lists the metadata for all clients", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/meta"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`[` + clientMetadataResponse + `, {"clientId":"other","showOnHomePage":false}]`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			metadata, err := a.ListClientMetadata()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metadata).To(HaveLen(2))
+			Expect(metadata[1].ClientID).To(Equal("other"))
+		})
+	})
+}
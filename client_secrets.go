@@ -0,0 +1,121 @@
+package uaa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	cc "golang.org/x/oauth2/clientcredentials"
+)
+
+// ClientSecretChangeMode is the operation mode for ChangeClientSecretWithRequest.
+type ClientSecretChangeMode string
+
+const (
+	// ClientSecretChangeModeAdd adds the secret alongside the client's
+	// current secret, so that both are accepted.
+	ClientSecretChangeModeAdd = ClientSecretChangeMode("ADD")
+	// ClientSecretChangeModeDelete deletes the older of the client's two
+	// secrets, leaving only the most recently added one.
+	ClientSecretChangeModeDelete = ClientSecretChangeMode("DELETE")
+)
+
+// ClientSecretChangeRequest is the request body for the PUT
+// /oauth/clients/{id}/secret endpoint. When ChangeMode is empty the client's
+// secret is replaced outright.
+type ClientSecretChangeRequest struct {
+	ClientID   string                 `json:"clientId,omitempty"`
+	OldSecret  string                 `json:"oldSecret,omitempty"`
+	Secret     string                 `json:"secret,omitempty"`
+	ChangeMode ClientSecretChangeMode `json:"changeMode,omitempty"`
+}
+
+func (r ClientSecretChangeRequest) validate() error {
+	if r.ClientID == "" {
+		return errorMissingValue("clientId")
+	}
+	if r.ChangeMode != ClientSecretChangeModeDelete && r.Secret == "" {
+		return errorMissingValue("secret")
+	}
+	return nil
+}
+
+// ChangeClientSecretWithRequest changes the secret of the client identified
+// by the request's ClientID, honoring the request's change mode
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#change-secret.
+func (a *API) ChangeClientSecretWithRequest(req ClientSecretChangeRequest) error {
+	if err := req.validate(); err != nil {
+		return err
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/secret", ClientsEndpoint, req.ClientID))
+	j, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return a.doJSON(http.MethodPut, &u, bytes.NewBuffer(j), nil, true)
+}
+
+// RotateClientSecret replaces the secret of the client with the given id
+// without a window in which neither secret works. The new secret is added
+// alongside the current one, verified by obtaining a client_credentials token
+// with it, and only then is the old secret deleted. oldSecret is only
+// required when the API is authenticated as the client itself.
+//
+// If the new secret cannot be verified, the old secret is left in place and
+// both secrets remain active.
+func (a *API) RotateClientSecret(id string, oldSecret string, newSecret string) error {
+	err := a.ChangeClientSecretWithRequest(ClientSecretChangeRequest{
+		ClientID:   id,
+		OldSecret:  oldSecret,
+		Secret:     newSecret,
+		ChangeMode: ClientSecretChangeModeAdd,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := a.verifyClientSecret(id, newSecret); err != nil {
+		return fmt.Errorf("the new secret for client %v could not be verified, so the old secret was kept: %v", id, err)
+	}
+
+	return a.ChangeClientSecretWithRequest(ClientSecretChangeRequest{
+		ClientID:   id,
+		OldSecret:  oldSecret,
+		ChangeMode: ClientSecretChangeModeDelete,
+	})
+}
+
+func (a *API) verifyClientSecret(id string, secret string) error {
+	tokenURL, err := a.endpointURL(tokenEndpointPath)
+	if err != nil {
+		return err
+	}
+	if a.zoneID != "" {
+		// UAA only honors the zone header for zone administrators, so a
+		// client in another zone must get its token at the zone's subdomain.
+		zone, err := a.GetIdentityZone(a.zoneID)
+		if err != nil {
+			return err
+		}
+		zoneURL, err := BuildSubdomainURL(a.TargetURL.String(), zone.Subdomain)
+		if err != nil {
+			return err
+		}
+		tokenURL = urlWithPath(*zoneURL, tokenEndpointPath)
+	}
+	c := &cc.Config{
+		ClientID:     id,
+		ClientSecret: secret,
+		TokenURL:     tokenURL.String(),
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+	ctx := context.Background()
+	if a.baseClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, a.baseClient)
+	}
+	_, err = c.Token(ctx)
+	return err
+}
@@ -0,0 +1,159 @@
+package uaa_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testClientSecrets(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("ChangeClientSecretWithRequest()", func() {
+		it("sends the change mode and old secret", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/my-client/secret"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"clientId":"my-client","oldSecret":"old","secret":"new","changeMode":"ADD"}`))
+				w.WriteHeader(http.StatusOK)
+			})
+			err := a.ChangeClientSecretWithRequest(uaa.ClientSecretChangeRequest{
+				ClientID:   "my-client",
+				OldSecret:  "old",
+				Secret:     "new",
+				ChangeMode: uaa.ClientSecretChangeModeAdd,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("does not require a secret when deleting", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"clientId":"my-client","changeMode":"DELETE"}`))
+				w.WriteHeader(http.StatusOK)
+			})
+			err := a.ChangeClientSecretWithRequest(uaa.ClientSecretChangeRequest{
+				ClientID:   "my-client",
+				ChangeMode: uaa.ClientSecretChangeModeDelete,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns an error when the secret is missing", func() {
+			err := a.ChangeClientSecretWithRequest(uaa.ClientSecretChangeRequest{
+				ClientID:   "my-client",
+				ChangeMode: uaa.ClientSecretChangeModeAdd,
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("RotateClientSecret()", func() {
+		var (
+			changes    []string
+			zoneIDs    []string
+			tokenHost  string
+			tokenError bool
+		)
+
+		it.Before(func() {
+			changes = nil
+			zoneIDs = nil
+			tokenHost = ""
+			tokenError = false
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case uaa.IdentityZonesEndpoint + "/zone-1":
+					w.WriteHeader(http.StatusOK)
+					_, err := w.Write([]byte(`{"id":"zone-1","subdomain":"zone1","name":"zone1"}`))
+					Expect(err).NotTo(HaveOccurred())
+				case uaa.ClientsEndpoint + "/my-client/secret":
+					Expect(req.Method).To(Equal(http.MethodPut))
+					zoneIDs = append(zoneIDs, req.Header.Get("X-Identity-Zone-Id"))
+					defer req.Body.Close()
+					body, _ := ioutil.ReadAll(req.Body)
+					changes = append(changes, string(body))
+					w.WriteHeader(http.StatusOK)
+				case "/oauth/token":
+					user, pass, ok := req.BasicAuth()
+					Expect(ok).To(BeTrue())
+					Expect(user).To(Equal("my-client"))
+					Expect(pass).To(Equal("new"))
+					Expect(req.FormValue("grant_type")).To(Equal("client_credentials"))
+					tokenHost = req.Host
+					if tokenError {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					_, err := w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+					Expect(err).NotTo(HaveOccurred())
+				default:
+					t.Errorf("unexpected request to %v", req.URL.Path)
+				}
+			})
+		})
+
+		it("adds the new secret, verifies it, then deletes the old secret", func() {
+			err := a.RotateClientSecret("my-client", "old", "new")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(3))
+			Expect(changes).To(HaveLen(2))
+			Expect(changes[0]).To(MatchJSON(`{"clientId":"my-client","oldSecret":"old","secret":"new","changeMode":"ADD"}`))
+			Expect(changes[1]).To(MatchJSON(`{"clientId":"my-client","oldSecret":"old","changeMode":"DELETE"}`))
+		})
+
+		it("keeps the old secret when the new secret cannot be verified", func() {
+			tokenError = true
+			err := a.RotateClientSecret("my-client", "old", "new")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("old secret was kept"))
+			Expect(changes).To(HaveLen(1))
+		})
+
+		it("verifies the new secret in the API's zone", func() {
+			target, err := url.Parse(s.URL)
+			Expect(err).NotTo(HaveOccurred())
+			api, err := uaa.New("http://login.example.com", uaa.WithNoAuthentication(), uaa.WithClient(&http.Client{Transport: &hostRewritingTransport{target: target}}))
+			Expect(err).NotTo(HaveOccurred())
+			err = api.ForZone("zone-1").RotateClientSecret("my-client", "old", "new")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zoneIDs).To(Equal([]string{"zone-1", "zone-1"}))
+			Expect(tokenHost).To(Equal("zone1.login.example.com"))
+		})
+	})
+}
@@ -0,0 +1,152 @@
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ClientAction is the action to perform on a client in a ModifyClients
+// transaction.
+type ClientAction string
+
+// Valid ClientAction values.
+const (
+	ClientActionAdd          = ClientAction("add")
+	ClientActionUpdate       = ClientAction("update")
+	ClientActionUpdateSecret = ClientAction("update,secret")
+	ClientActionDelete       = ClientAction("delete")
+	ClientActionSecret       = ClientAction("secret")
+)
+
+// ClientModification is a client and the action to perform on it in a
+// ModifyClients transaction.
+type ClientModification struct {
+	Client
+	Action ClientAction `json:"action"`
+}
+
+// MarshalJSON encodes the client together with its action. Without it the
+// promoted Client.MarshalJSON would encode the client alone.
+func (m ClientModification) MarshalJSON() ([]byte, error) {
+	action, err := json.Marshal(m.Action)
+	if err != nil {
+		return nil, err
+	}
+	extra := map[string]json.RawMessage{"action": action}
+	for name, value := range m.Extra {
+		if name != "action" {
+			extra[name] = value
+		}
+	}
+	client := m.Client
+	client.Extra = extra
+	return json.Marshal(client)
+}
+
+// UnmarshalJSON decodes the client and its action.
+func (m *ClientModification) UnmarshalJSON(data []byte) error {
+	var action struct {
+		Action ClientAction `json:"action"`
+	}
+	if err := json.Unmarshal(data, &action); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &m.Client); err != nil {
+		return err
+	}
+	delete(m.Client.Extra, "action")
+	if len(m.Client.Extra) == 0 {
+		m.Client.Extra = nil
+	}
+	m.Action = action.Action
+	return nil
+}
+
+// CreateClients creates the given clients in a single transaction: either all
+// of the clients are created or, if any client is invalid, none are
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#batch-create.
+func (a *API) CreateClients(clients []Client) ([]Client, error) {
+	var created []Client
+	err := a.doClientTransaction(http.MethodPost, "tx", clients, &created)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateClients updates the given clients in a single transaction: either all
+// of the clients are updated or none are
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#batch-update.
+func (a *API) UpdateClients(clients []Client) ([]Client, error) {
+	var updated []Client
+	err := a.doClientTransaction(http.MethodPut, "tx", clients, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ChangeClientSecrets changes the secrets of several clients in a single
+// transaction: either all of the secrets are changed or none are
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#change-secret-2.
+func (a *API) ChangeClientSecrets(reqs []ClientSecretChangeRequest) ([]Client, error) {
+	for _, req := range reqs {
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+	}
+	var changed []Client
+	err := a.doClientTransaction(http.MethodPost, "tx/secret", reqs, &changed)
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// DeleteClients deletes the clients with the given IDs in a single
+// transaction: either all of the clients are deleted or none are
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#batch-delete.
+func (a *API) DeleteClients(clientIDs []string) ([]Client, error) {
+	clients := make([]Client, 0, len(clientIDs))
+	for _, id := range clientIDs {
+		if id == "" {
+			return nil, errors.New("clientID cannot be blank")
+		}
+		clients = append(clients, Client{ClientID: id})
+	}
+	var deleted []Client
+	err := a.doClientTransaction(http.MethodPost, "tx/delete", clients, &deleted)
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ModifyClients applies the given per-client actions in a single transaction:
+// either all of the modifications are applied or none are
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#mixed-actions.
+func (a *API) ModifyClients(modifications []ClientModification) ([]ClientModification, error) {
+	for _, m := range modifications {
+		if m.Action == "" {
+			return nil, fmt.Errorf("action must be specified for client %v", m.ClientID)
+		}
+	}
+	var modified []ClientModification
+	err := a.doClientTransaction(http.MethodPost, "tx/modify", modifications, &modified)
+	if err != nil {
+		return nil, err
+	}
+	return modified, nil
+}
+
+func (a *API) doClientTransaction(method string, path string, body interface{}, response interface{}) error {
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", ClientsEndpoint, path))
+	j, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return a.doJSON(method, &u, bytes.NewBuffer([]byte(j)), response, true)
+}
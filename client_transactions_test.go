@@ -0,0 +1,186 @@
+package uaa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testClientTransactions(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	clients := []uaa.Client{
+		{ClientID: "client-1", AuthorizedGrantTypes: []string{"client_credentials"}, ClientSecret: "secret-1"},
+		{ClientID: "client-2", AuthorizedGrantTypes: []string{"client_credentials"}, ClientSecret: "secret-2"},
+	}
+	clientsJSON := `[
+		{"client_id":"client-1","authorized_grant_types":["client_credentials"],"client_secret":"secret-1"},
+		{"client_id":"client-2","authorized_grant_types":["client_credentials"],"client_secret":"secret-2"}
+	]`
+	clientsResponse := `[{"client_id":"client-1"},{"client_id":"client-2"}]`
+
+	when("CreateClients()", func() {
+		it("POSTs the clients to /oauth/clients/tx", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/tx"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(clientsJSON))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(clientsResponse))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			created, err := a.CreateClients(clients)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(HaveLen(2))
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns an error when the transaction fails", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, err := w.Write([]byte(`{"error":"invalid_client"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			created, err := a.CreateClients(clients)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid_client"))
+			Expect(created).To(BeNil())
+		})
+	})
+
+	when("UpdateClients()", func() {
+		it("PUTs the clients to /oauth/clients/tx", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/tx"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(clientsJSON))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(clientsResponse))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			updated, err := a.UpdateClients(clients)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(HaveLen(2))
+		})
+	})
+
+	when("ChangeClientSecrets()", func() {
+		it("POSTs the changes to /oauth/clients/tx/secret", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/tx/secret"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`[{"clientId":"client-1","secret":"s1"},{"clientId":"client-2","secret":"s2"}]`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`[{"client_id":"client-1"},{"client_id":"client-2"}]`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			changed, err := a.ChangeClientSecrets([]uaa.ClientSecretChangeRequest{
+				{ClientID: "client-1", Secret: "s1"},
+				{ClientID: "client-2", Secret: "s2"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(HaveLen(2))
+		})
+	})
+
+	when("DeleteClients()", func() {
+		it("POSTs the client IDs to /oauth/clients/tx/delete", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/tx/delete"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`[{"client_id":"client-1"},{"client_id":"client-2"}]`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(clientsResponse))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			deleted, err := a.DeleteClients([]string{"client-1", "client-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(HaveLen(2))
+		})
+
+		it("returns an error when a clientID is empty", func() {
+			_, err := a.DeleteClients([]string{"client-1", ""})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("ModifyClients()", func() {
+		it("POSTs the clients and their actions to /oauth/clients/tx/modify", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint + "/tx/modify"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`[
+					{"client_id":"client-1","authorized_grant_types":["client_credentials"],"client_secret":"secret-1","action":"add"},
+					{"client_id":"client-2","action":"delete"}
+				]`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`[{"client_id":"client-1","action":"add"},{"client_id":"client-2","action":"delete"}]`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			modified, err := a.ModifyClients([]uaa.ClientModification{
+				{Client: clients[0], Action: uaa.ClientActionAdd},
+				{Client: uaa.Client{ClientID: "client-2"}, Action: uaa.ClientActionDelete},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(HaveLen(2))
+			Expect(modified[1].ClientID).To(Equal("client-2"))
+			Expect(modified[1].Action).To(Equal(uaa.ClientActionDelete))
+		})
+
+		it("returns an error when an action is missing", func() {
+			_, err := a.ModifyClients([]uaa.ClientModification{{Client: clients[0]}})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("keeps the action and the client's unmodelled members apart", func() {
+			modification := uaa.ClientModification{}
+			Expect(json.Unmarshal([]byte(`{"client_id":"client-1","action":"update","additional_information":{"a":1}}`), &modification)).To(Succeed())
+			Expect(modification.Action).To(Equal(uaa.ClientActionUpdate))
+			Expect(modification.Extra).To(Equal(map[string]json.RawMessage{"additional_information": json.RawMessage(`{"a":1}`)}))
+			j, err := json.Marshal(modification)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"client_id":"client-1","action":"update","additional_information":{"a":1}}`))
+		})
+	})
+}
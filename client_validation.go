@@ -0,0 +1,267 @@
+package uaa
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// knownGrantTypes are the grant types supported by UAA.
+var knownGrantTypes = []GrantType{
+	AUTHCODE,
+	IMPLICIT,
+	PASSWORD,
+	CLIENTCREDENTIALS,
+	REFRESHTOKEN,
+	JWTBEARER,
+	SAML2BEARER,
+	USERTOKEN,
+	TOKENEXCHANGE,
+}
+
+// userGrantTypes are the grant types that obtain a token on behalf of a user,
+// and so may be paired with refresh_token.
+var userGrantTypes = []GrantType{
+	AUTHCODE,
+	PASSWORD,
+	JWTBEARER,
+	SAML2BEARER,
+	USERTOKEN,
+	TOKENEXCHANGE,
+}
+
+// maxTokenValidity is the largest token validity, in seconds, that UAA can
+// store for a client.
+const maxTokenValidity = math.MaxInt32
+
+func errorMissingValueForGrantType(value string, grantType GrantType) error {
+	return fmt.Errorf("%v must be specified for %v grant type", value, grantType)
+}
+
+func errorMissingValue(value string) error {
+	return fmt.Errorf("%v must be specified in the client definition", value)
+}
+
+func requireRedirectURIForGrantType(c *Client, grantType GrantType) error {
+	if contains(c.AuthorizedGrantTypes, string(grantType)) {
+		if len(c.RedirectURI) == 0 {
+			return errorMissingValueForGrantType("redirect_uri", grantType)
+		}
+	}
+	return nil
+}
+
+func requireClientSecretForGrantType(c *Client, grantType GrantType) error {
+	if contains(c.AuthorizedGrantTypes, string(grantType)) {
+		if c.ClientSecret == "" {
+			return errorMissingValueForGrantType("client_secret", grantType)
+		}
+	}
+	return nil
+}
+
+func grantTypesStr(grantTypes []GrantType) string {
+	grantTypeStrings := []string{}
+	for _, grant := range grantTypes {
+		grantTypeStrings = append(grantTypeStrings, string(grant))
+	}
+
+	return "[" + strings.Join(grantTypeStrings, ", ") + "]"
+}
+
+func knownGrantTypesStr() string {
+	return grantTypesStr(knownGrantTypes)
+}
+
+func isKnownGrantType(grantType string) bool {
+	for _, known := range knownGrantTypes {
+		if grantType == string(known) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasUserGrantType(c *Client) bool {
+	for _, grant := range userGrantTypes {
+		if contains(c.AuthorizedGrantTypes, string(grant)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns nil if the client is valid. If it is invalid, it returns
+// an error describing every problem found; use errors.Is, errors.As or the
+// Unwrap() []error method to inspect them individually.
+func (c *Client) Validate() error {
+	var errs []error
+
+	if len(c.AuthorizedGrantTypes) == 0 {
+		errs = append(errs, fmt.Errorf("grant type must be one of %v", knownGrantTypesStr()))
+	}
+	for _, grant := range c.AuthorizedGrantTypes {
+		if !isKnownGrantType(grant) {
+			errs = append(errs, fmt.Errorf("%v is not a valid grant type, must be one of %v", grant, knownGrantTypesStr()))
+		}
+	}
+
+	if c.ClientID == "" {
+		errs = append(errs, errorMissingValue("client_id"))
+	}
+
+	errs = append(errs,
+		requireRedirectURIForGrantType(c, AUTHCODE),
+		requireClientSecretForGrantType(c, AUTHCODE),
+		requireClientSecretForGrantType(c, CLIENTCREDENTIALS),
+		requireRedirectURIForGrantType(c, IMPLICIT),
+	)
+
+	if contains(c.AuthorizedGrantTypes, string(IMPLICIT)) && c.ClientSecret != "" {
+		errs = append(errs, fmt.Errorf("client_secret must not be specified for %v grant type", IMPLICIT))
+	}
+	if contains(c.AuthorizedGrantTypes, string(JWTBEARER)) && len(c.Scope) == 0 {
+		errs = append(errs, errorMissingValueForGrantType("scope", JWTBEARER))
+	}
+	if contains(c.AuthorizedGrantTypes, string(REFRESHTOKEN)) && !hasUserGrantType(c) {
+		errs = append(errs, fmt.Errorf("%v grant type must be combined with one of %v", REFRESHTOKEN, grantTypesStr(userGrantTypes)))
+	}
+
+	for _, uri := range c.RedirectURI {
+		errs = append(errs, validateRedirectURI(uri))
+	}
+	errs = append(errs, validateScopes("scope", c.Scope)...)
+	errs = append(errs, validateScopes("authorities", c.Authorities)...)
+	errs = append(errs,
+		validateTokenValidity("access_token_validity", c.AccessTokenValidity),
+		validateTokenValidity("refresh_token_validity", c.RefreshTokenValidity),
+	)
+
+	if c.JwksURI != "" && len(c.Jwks) > 0 {
+		errs = append(errs, errors.New("only one of jwks_uri and jwks may be specified"))
+	}
+	if c.JwksURI != "" {
+		if u, err := url.Parse(c.JwksURI); err != nil || !u.IsAbs() || u.Host == "" {
+			errs = append(errs, fmt.Errorf("jwks_uri %v must be an absolute URL", c.JwksURI))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateForZone validates the client as Validate does, and also checks the
+// client's secret against the client secret policy of the given zone.
+func (c *Client) ValidateForZone(zone IdentityZone) error {
+	errs := []error{c.Validate()}
+	if c.ClientSecret != "" && zone.Config.ClientSecretPolicy != nil {
+		errs = append(errs, zone.Config.ClientSecretPolicy.Validate(c.ClientSecret))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate returns nil if the secret satisfies the policy, or an error
+// describing every requirement the secret does not meet.
+func (p ClientSecretPolicy) Validate(secret string) error {
+	var upper, lower, digit, special int
+	for _, r := range secret {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digit++
+		default:
+			special++
+		}
+	}
+
+	var errs []error
+	length := len([]rune(secret))
+	if p.MinLength > 0 && length < p.MinLength {
+		errs = append(errs, fmt.Errorf("client_secret must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		errs = append(errs, fmt.Errorf("client_secret must be at most %d characters long", p.MaxLength))
+	}
+	if upper < p.RequireUpperCaseCharacter {
+		errs = append(errs, fmt.Errorf("client_secret must contain at least %d uppercase characters", p.RequireUpperCaseCharacter))
+	}
+	if lower < p.RequireLowerCaseCharacter {
+		errs = append(errs, fmt.Errorf("client_secret must contain at least %d lowercase characters", p.RequireLowerCaseCharacter))
+	}
+	if digit < p.RequireDigit {
+		errs = append(errs, fmt.Errorf("client_secret must contain at least %d digits", p.RequireDigit))
+	}
+	if special < p.RequireSpecialCharacter {
+		errs = append(errs, fmt.Errorf("client_secret must contain at least %d special characters", p.RequireSpecialCharacter))
+	}
+	return errors.Join(errs...)
+}
+
+// validateRedirectURI applies UAA's rules for registered redirect URIs: the
+// URI must be absolute, and wildcards may not appear in the scheme or stand
+// in for the whole host or for a top-level domain.
+func validateRedirectURI(uri string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("redirect_uri %v is invalid: %v", uri, reason)
+	}
+	if strings.TrimSpace(uri) == "" || uri == "*" {
+		return invalid("it must be an absolute URL")
+	}
+	i := strings.Index(uri, "://")
+	if i <= 0 {
+		return invalid("it must be an absolute URL")
+	}
+	if strings.Contains(uri[:i], "*") {
+		return invalid("wildcards are not allowed in the scheme")
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	host := u.Hostname()
+	if host == "" {
+		return invalid("it must have a host")
+	}
+	if !strings.Contains(host, "*") {
+		return nil
+	}
+	labels := strings.Split(host, ".")
+	if strings.Contains(strings.Join(labels[1:], "."), "*") {
+		return invalid("a wildcard is only allowed as the leftmost label of the host")
+	}
+	if len(labels) < 3 {
+		return invalid("a wildcard host must be a subdomain of a registrable domain")
+	}
+	return nil
+}
+
+// validateScopes checks that each scope is non-empty and contains no
+// whitespace or commas, which UAA uses as scope separators.
+func validateScopes(field string, scopes []string) []error {
+	var errs []error
+	for _, scope := range scopes {
+		if scope == "" {
+			errs = append(errs, fmt.Errorf("%v must not contain empty values", field))
+			continue
+		}
+		if strings.ContainsFunc(scope, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+			errs = append(errs, fmt.Errorf("%v value %q must not contain whitespace or commas", field, scope))
+		}
+	}
+	return errs
+}
+
+func validateTokenValidity(field string, validity int64) error {
+	if validity < 0 || validity > maxTokenValidity {
+		return fmt.Errorf("%v must be between 0 and %d seconds", field, maxTokenValidity)
+	}
+	return nil
+}
@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 )
 
 // ClientsEndpoint is the path to the clients resource.
@@ -28,7 +26,7 @@ type Client struct {
 	Scope                []string        `json:"scope,omitempty"`
 	ResourceIDs          []string        `json:"resource_ids,omitempty"`
 	Authorities          []string        `json:"authorities,omitempty"`
-	AutoApproveRaw       interface{}     `json:"autoapprove,omitempty"`
+	AutoApprove          AutoApprove     `json:"autoapprove,omitzero"`
 	AccessTokenValidity  int64           `json:"access_token_validity,omitempty"`
 	RefreshTokenValidity int64           `json:"refresh_token_validity,omitempty"`
 	AllowedProviders     []string        `json:"allowedproviders,omitempty"`
@@ -42,6 +40,9 @@ type Client struct {
 	AllowPublic          bool            `json:"allowpublic,omitempty"`
 	JwksURI              string          `json:"jwks_uri,omitempty"`
 	Jwks                 json.RawMessage `json:"jwks,omitempty"`
+	// Extra holds the members UAA returned that Client does not model, such
+	// as additional_information.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Identifier returns the field used to uniquely identify a Client.
@@ -49,18 +50,6 @@ func (c Client) Identifier() string {
 	return c.ClientID
 }
 
-func (c Client) AutoApprove() []string {
-	switch t := c.AutoApproveRaw.(type) {
-	case bool:
-		return []string{strconv.FormatBool(t)}
-	case string:
-		return []string{t}
-	case []string:
-		return t
-	}
-	return []string{}
-}
-
 // GrantType is a type of oauth2 grant.
 type GrantType string
 
@@ -71,92 +60,17 @@ const (
 	IMPLICIT          = GrantType("implicit")
 	PASSWORD          = GrantType("password")
 	CLIENTCREDENTIALS = GrantType("client_credentials")
+	JWTBEARER         = GrantType("urn:ietf:params:oauth:grant-type:jwt-bearer")
+	SAML2BEARER       = GrantType("urn:ietf:params:oauth:grant-type:saml2-bearer")
+	USERTOKEN         = GrantType("user_token")
+	TOKENEXCHANGE     = GrantType("urn:ietf:params:oauth:grant-type:token-exchange")
 )
 
-func errorMissingValueForGrantType(value string, grantType GrantType) error {
-	return fmt.Errorf("%v must be specified for %v grant type", value, grantType)
-}
-
-func errorMissingValue(value string) error {
-	return fmt.Errorf("%v must be specified in the client definition", value)
-}
-
-func requireRedirectURIForGrantType(c *Client, grantType GrantType) error {
-	if contains(c.AuthorizedGrantTypes, string(grantType)) {
-		if len(c.RedirectURI) == 0 {
-			return errorMissingValueForGrantType("redirect_uri", grantType)
-		}
-	}
-	return nil
-}
-
-func requireClientSecretForGrantType(c *Client, grantType GrantType) error {
-	if contains(c.AuthorizedGrantTypes, string(grantType)) {
-		if c.ClientSecret == "" {
-			return errorMissingValueForGrantType("client_secret", grantType)
-		}
-	}
-	return nil
-}
-
-func knownGrantTypesStr() string {
-	grantTypeStrings := []string{}
-	knownGrantTypes := []GrantType{AUTHCODE, IMPLICIT, PASSWORD, CLIENTCREDENTIALS}
-	for _, grant := range knownGrantTypes {
-		grantTypeStrings = append(grantTypeStrings, string(grant))
-	}
-
-	return "[" + strings.Join(grantTypeStrings, ", ") + "]"
-}
-
-// Validate returns nil if the client is valid, or an error if it is invalid.
-func (c *Client) Validate() error {
-	if len(c.AuthorizedGrantTypes) == 0 {
-		return fmt.Errorf("grant type must be one of %v", knownGrantTypesStr())
-	}
-
-	if c.ClientID == "" {
-		return errorMissingValue("client_id")
-	}
-
-	if err := requireRedirectURIForGrantType(c, AUTHCODE); err != nil {
-		return err
-	}
-	if err := requireClientSecretForGrantType(c, AUTHCODE); err != nil {
-		return err
-	}
-
-	if err := requireClientSecretForGrantType(c, CLIENTCREDENTIALS); err != nil {
-		return err
-	}
-
-	if err := requireRedirectURIForGrantType(c, IMPLICIT); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-type changeSecretBody struct {
-	ClientID     string `json:"clientId,omitempty"`
-	ClientSecret string `json:"secret,omitempty"`
-}
-
 // ChangeClientSecret updates the secret with the given value for the client
 // with the given id
 // http://docs.cloudfoundry.org/api/uaa/version/4.14.0/index.html#change-secret.
 func (a *API) ChangeClientSecret(id string, newSecret string) error {
-	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/secret", ClientsEndpoint, id))
-	change := &changeSecretBody{ClientID: id, ClientSecret: newSecret}
-	j, err := json.Marshal(change)
-	if err != nil {
-		return err
-	}
-	err = a.doJSON(http.MethodPut, &u, bytes.NewBuffer([]byte(j)), nil, true)
-	if err != nil {
-		return err
-	}
-	return nil
+	return a.ChangeClientSecretWithRequest(ClientSecretChangeRequest{ClientID: id, Secret: newSecret})
 }
 
 // ChangeClientJWTMode is the operation mode for ChangeClientJWT.
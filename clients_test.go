@@ -100,7 +100,7 @@ func testClientExtra(t *testing.T, when spec.G, it spec.S) {
 			it("decodes the autoapprove value", func() {
 				client, err := a.GetClient("00000000-0000-0000-0000-000000000001")
 				Expect(err).NotTo(HaveOccurred())
-				Expect(client.AutoApprove()).To(Equal([]string{"true"}))
+				Expect(client.AutoApprove.Strings()).To(Equal([]string{"true"}))
 			})
 		})
 
@@ -132,16 +132,273 @@ func testClientExtra(t *testing.T, when spec.G, it spec.S) {
 			it("decodes the autoapprove value", func() {
 				client, err := a.GetClient("00000000-0000-0000-0000-000000000001")
 				Expect(err).NotTo(HaveOccurred())
-				Expect(client.AutoApprove()).To(Equal([]string{"scope"}))
+				Expect(client.AutoApprove.Strings()).To(Equal([]string{"scope"}))
+			})
+		})
+
+		when("the client returned from the server contains an autoapprove value that is an array", func() {
+			response := `{
+      	"client_id" : "00000000-0000-0000-0000-000000000001",
+      	"authorized_grant_types" : [ "authorization_code" ],
+      	"autoapprove" : [ "openid", "cloud_controller.read" ]
+      }`
+
+			it.Before(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", uaa.ClientsEndpoint+"/00000000-0000-0000-0000-000000000001"),
+					ghttp.RespondWith(http.StatusOK, response),
+				))
+			})
+
+			it("decodes the autoapprove value", func() {
+				client, err := a.GetClient("00000000-0000-0000-0000-000000000001")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.AutoApprove.Strings()).To(Equal([]string{"openid", "cloud_controller.read"}))
+				Expect(client.AutoApprove.Approves("openid")).To(BeTrue())
+				Expect(client.AutoApprove.Approves("uaa.admin")).To(BeFalse())
 			})
 		})
 	})
 
+	when("AutoApprove", func() {
+		it.Before(func() {
+			RegisterTestingT(t)
+		})
+
+		for _, value := range []string{`true`, `false`, `"true"`, `"openid"`, `"openid,profile"`, `["openid","profile"]`, `[]`} {
+			value := value
+			it("round-trips "+value+" losslessly", func() {
+				var client uaa.Client
+				Expect(json.Unmarshal([]byte(`{"client_id":"c","autoapprove":`+value+`}`), &client)).To(Succeed())
+				j, err := json.Marshal(client)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(j).To(MatchJSON(`{"client_id":"c","autoapprove":` + value + `}`))
+			})
+		}
+
+		it("approves every scope when true", func() {
+			var client uaa.Client
+			Expect(json.Unmarshal([]byte(`{"autoapprove":"true"}`), &client)).To(Succeed())
+			Expect(client.AutoApprove.All).To(BeTrue())
+			Expect(client.AutoApprove.Approves("anything")).To(BeTrue())
+		})
+
+		it("omits an unset value", func() {
+			j, err := json.Marshal(uaa.Client{ClientID: "c"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"client_id":"c"}`))
+		})
+
+		it("encodes values built in code", func() {
+			j, err := json.Marshal(uaa.Client{ClientID: "c", AutoApprove: uaa.AutoApproveAll()})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"client_id":"c","autoapprove":true}`))
+
+			j, err = json.Marshal(uaa.Client{ClientID: "c", AutoApprove: uaa.AutoApproveScopes("openid")})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"client_id":"c","autoapprove":["openid"]}`))
+		})
+
+		it("returns the same scopes it encodes", func() {
+			var client uaa.Client
+			Expect(json.Unmarshal([]byte(`{"autoapprove":false}`), &client)).To(Succeed())
+			client.AutoApprove.Scopes = []string{"openid"}
+			j, err := json.Marshal(client.AutoApprove)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`["openid"]`))
+			Expect(client.AutoApprove.Strings()).To(Equal([]string{"openid"}))
+
+			Expect(json.Unmarshal([]byte(`{"autoapprove":"openid, profile"}`), &client)).To(Succeed())
+			Expect(client.AutoApprove.Strings()).To(Equal([]string{"openid", "profile"}))
+		})
+
+		it("rejects values of other types", func() {
+			var client uaa.Client
+			Expect(json.Unmarshal([]byte(`{"autoapprove":1}`), &client)).NotTo(Succeed())
+			Expect(json.Unmarshal([]byte(`{"autoapprove":[1]}`), &client)).NotTo(Succeed())
+		})
+	})
+
 	when("Client.Validate()", func() {
 		it("rejects empty grant types", func() {
 			client := uaa.Client{}
 			err := client.Validate()
-			Expect(err.Error()).To(Equal(`grant type must be one of [authorization_code, implicit, password, client_credentials]`))
+			Expect(err.Error()).To(ContainSubstring(`grant type must be one of [authorization_code, implicit, password, client_credentials, refresh_token, urn:ietf:params:oauth:grant-type:jwt-bearer, urn:ietf:params:oauth:grant-type:saml2-bearer, user_token, urn:ietf:params:oauth:grant-type:token-exchange]`))
+		})
+
+		it("returns all problems at once", func() {
+			client := uaa.Client{
+				AuthorizedGrantTypes: []string{"authorization_code", "not_a_grant"},
+			}
+			err := client.Validate()
+			Expect(err).NotTo(BeNil())
+			errs := err.(interface{ Unwrap() []error }).Unwrap()
+			Expect(errs).To(HaveLen(4))
+			Expect(err.Error()).To(ContainSubstring("not_a_grant is not a valid grant type"))
+			Expect(err.Error()).To(ContainSubstring("client_id must be specified in the client definition"))
+			Expect(err.Error()).To(ContainSubstring("redirect_uri must be specified for authorization_code grant type"))
+			Expect(err.Error()).To(ContainSubstring("client_secret must be specified for authorization_code grant type"))
+		})
+
+		it("accepts the JWT bearer, SAML bearer, user token and token exchange grant types", func() {
+			client := uaa.Client{
+				ClientID:             "myclient",
+				ClientSecret:         "secret",
+				Scope:                []string{"openid"},
+				AuthorizedGrantTypes: []string{string(uaa.JWTBEARER), string(uaa.SAML2BEARER), string(uaa.USERTOKEN), string(uaa.TOKENEXCHANGE)},
+			}
+			Expect(client.Validate()).To(BeNil())
+		})
+
+		it("requires scope for the JWT bearer grant type", func() {
+			client := uaa.Client{
+				ClientID:             "myclient",
+				AuthorizedGrantTypes: []string{string(uaa.JWTBEARER)},
+			}
+			err := client.Validate()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal("scope must be specified for urn:ietf:params:oauth:grant-type:jwt-bearer grant type"))
+		})
+
+		it("rejects a client_secret for the implicit grant type", func() {
+			client := uaa.Client{
+				ClientID:             "myclient",
+				ClientSecret:         "secret",
+				AuthorizedGrantTypes: []string{"implicit"},
+				RedirectURI:          []string{"http://localhost:8080"},
+			}
+			err := client.Validate()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal("client_secret must not be specified for implicit grant type"))
+		})
+
+		when("when refresh_token", func() {
+			it("requires a user grant type", func() {
+				client := uaa.Client{
+					ClientID:             "myclient",
+					ClientSecret:         "secret",
+					AuthorizedGrantTypes: []string{"client_credentials", "refresh_token"},
+				}
+				err := client.Validate()
+				Expect(err).NotTo(BeNil())
+				Expect(err.Error()).To(ContainSubstring("refresh_token grant type must be combined with one of [authorization_code, password"))
+			})
+
+			it("accepts refresh_token paired with password", func() {
+				client := uaa.Client{
+					ClientID:             "myclient",
+					ClientSecret:         "secret",
+					AuthorizedGrantTypes: []string{"password", "refresh_token"},
+				}
+				Expect(client.Validate()).To(BeNil())
+			})
+		})
+
+		when("redirect URIs contain wildcards", func() {
+			validate := func(uri string) error {
+				client := uaa.Client{
+					ClientID:             "myclient",
+					AuthorizedGrantTypes: []string{"implicit"},
+					RedirectURI:          []string{uri},
+				}
+				return client.Validate()
+			}
+
+			it("accepts wildcard subdomains and paths", func() {
+				Expect(validate("https://*.example.com/**")).To(BeNil())
+				Expect(validate("http://localhost:8080/callback/*")).To(BeNil())
+				Expect(validate("myapp://callback")).To(BeNil())
+			})
+
+			it("rejects wildcards that match too broadly", func() {
+				Expect(validate("*")).NotTo(BeNil())
+				Expect(validate("http*://example.com")).NotTo(BeNil())
+				Expect(validate("https://*")).NotTo(BeNil())
+				Expect(validate("https://*.com")).NotTo(BeNil())
+				Expect(validate("https://app.*.example.com")).NotTo(BeNil())
+				Expect(validate("/relative/path")).NotTo(BeNil())
+			})
+		})
+
+		it("rejects malformed scopes and authorities", func() {
+			client := uaa.Client{
+				ClientID:             "myclient",
+				ClientSecret:         "secret",
+				AuthorizedGrantTypes: []string{"client_credentials"},
+				Scope:                []string{"openid", "bad scope"},
+				Authorities:          []string{"uaa.admin,clients.read", ""},
+			}
+			err := client.Validate()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(ContainSubstring(`scope value "bad scope" must not contain whitespace or commas`))
+			Expect(err.Error()).To(ContainSubstring(`authorities value "uaa.admin,clients.read" must not contain whitespace or commas`))
+			Expect(err.Error()).To(ContainSubstring("authorities must not contain empty values"))
+		})
+
+		it("rejects token validities out of bounds", func() {
+			client := uaa.Client{
+				ClientID:             "myclient",
+				ClientSecret:         "secret",
+				AuthorizedGrantTypes: []string{"client_credentials"},
+				AccessTokenValidity:  -1,
+				RefreshTokenValidity: 1 << 40,
+			}
+			err := client.Validate()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(ContainSubstring("access_token_validity must be between 0 and 2147483647 seconds"))
+			Expect(err.Error()).To(ContainSubstring("refresh_token_validity must be between 0 and 2147483647 seconds"))
+		})
+
+		it("rejects both jwks and jwks_uri", func() {
+			client := uaa.Client{
+				ClientID:             "myclient",
+				AuthorizedGrantTypes: []string{string(uaa.JWTBEARER)},
+				Scope:                []string{"openid"},
+				JwksURI:              "https://example.com/token_keys",
+				Jwks:                 json.RawMessage(`{"keys":[]}`),
+			}
+			err := client.Validate()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal("only one of jwks_uri and jwks may be specified"))
+		})
+
+		when("ValidateForZone()", func() {
+			zone := uaa.IdentityZone{
+				Config: uaa.IdentityZoneConfig{
+					ClientSecretPolicy: &uaa.ClientSecretPolicy{
+						MinLength:                 8,
+						MaxLength:                 32,
+						RequireUpperCaseCharacter: 1,
+						RequireLowerCaseCharacter: 1,
+						RequireDigit:              1,
+						RequireSpecialCharacter:   1,
+					},
+				},
+			}
+
+			it("accepts a secret that satisfies the policy", func() {
+				client := uaa.Client{
+					ClientID:             "myclient",
+					ClientSecret:         "Secr3t-value",
+					AuthorizedGrantTypes: []string{"client_credentials"},
+				}
+				Expect(client.ValidateForZone(zone)).To(BeNil())
+			})
+
+			it("reports every unmet policy requirement", func() {
+				client := uaa.Client{
+					ClientID:             "myclient",
+					ClientSecret:         "secret",
+					AuthorizedGrantTypes: []string{"client_credentials"},
+				}
+				err := client.ValidateForZone(zone)
+				Expect(err).NotTo(BeNil())
+				Expect(err.Error()).To(ContainSubstring("client_secret must be at least 8 characters long"))
+				Expect(err.Error()).To(ContainSubstring("client_secret must contain at least 1 uppercase characters"))
+				Expect(err.Error()).To(ContainSubstring("client_secret must contain at least 1 digits"))
+				Expect(err.Error()).To(ContainSubstring("client_secret must contain at least 1 special characters"))
+				Expect(err.Error()).NotTo(ContainSubstring("lowercase"))
+			})
 		})
 
 		when("when authorization_code", func() {
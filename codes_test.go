@@ -0,0 +1,135 @@
+package uaa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+const expiringCodeResponse = `{
+	"code": "aOQ2tG",
+	"expiresAt": 1893456000000,
+	"data": "{\"user_id\":\"user-1\",\"client_id\":\"registration\"}",
+	"intent": "register-user"
+}`
+
+func testCodes(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("GenerateCode()", func() {
+		expiresAt := time.UnixMilli(1893456000000)
+
+		it("POSTs the data as a JSON string with the expiry in milliseconds", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.CodesEndpoint))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{
+					"expiresAt": 1893456000000,
+					"data": "{\"user_id\":\"user-1\",\"client_id\":\"registration\"}",
+					"intent": "register-user"
+				}`))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(expiringCodeResponse))
+			})
+			code, err := a.GenerateCode(json.RawMessage(`{"user_id":"user-1","client_id":"registration"}`), expiresAt, "register-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code.Code).To(Equal("aOQ2tG"))
+			Expect(code.ExpiresAt.Equal(expiresAt)).To(BeTrue())
+			Expect(code.Data).To(MatchJSON(`{"user_id":"user-1","client_id":"registration"}`))
+			Expect(code.Intent).To(Equal("register-user"))
+		})
+
+		it("rejects data that is not JSON", func() {
+			_, err := a.GenerateCode(json.RawMessage(`not json`), expiresAt, "")
+			Expect(err).To(MatchError("data must be valid JSON"))
+			Expect(called).To(Equal(0))
+		})
+
+		it("rejects an expiry in the past", func() {
+			_, err := a.GenerateCode(json.RawMessage(`{}`), time.Now().Add(-time.Minute), "")
+			Expect(err).To(MatchError("expiresAt must be in the future"))
+			Expect(called).To(Equal(0))
+		})
+
+		it("returns an error when UAA rejects the code", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			})
+			code, err := a.GenerateCode(json.RawMessage(`{}`), expiresAt, "")
+			Expect(err).To(HaveOccurred())
+			Expect(code).To(BeNil())
+		})
+	})
+
+	when("RetrieveCode()", func() {
+		it("GETs the code and decodes its data", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(uaa.CodesEndpoint + "/aOQ2tG"))
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(expiringCodeResponse))
+			})
+			code, err := a.RetrieveCode("aOQ2tG")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code.Data).To(MatchJSON(`{"user_id":"user-1","client_id":"registration"}`))
+			Expect(code.ExpiresAt.UnixMilli()).To(Equal(int64(1893456000000)))
+		})
+
+		it("decodes data that is not JSON as a JSON string", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"code":"abc","expiresAt":1893456000000,"data":"user-1"}`))
+			})
+			code, err := a.RetrieveCode("abc")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code.Data).To(MatchJSON(`"user-1"`))
+		})
+
+		it("returns an error when the code has expired or was already used", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+			code, err := a.RetrieveCode("aOQ2tG")
+			Expect(err).To(HaveOccurred())
+			Expect(code).To(BeNil())
+		})
+
+		it("returns an error when the code is blank", func() {
+			_, err := a.RetrieveCode("")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+}
@@ -23,7 +23,8 @@ func (a *API) Curl(path string, method string, data string, headers []string) (s
 		return "", "", -1, err
 	}
 
-	a.ensureTransport(a.Client.Transport)
+	req, cancel := withDefaultTimeout(a.Client, req)
+	defer cancel()
 	resp, err := a.Client.Do(req)
 	if err != nil {
 		if a.verbose {
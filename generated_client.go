@@ -4,9 +4,11 @@ package uaa
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -47,7 +49,7 @@ func (a *API) UpdateClient(client Client) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = a.doJSON(http.MethodPut, &u, bytes.NewBuffer([]byte(j)), created, true)
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +75,10 @@ func (a *API) DeleteClient(clientID string) (*Client, error) {
 // If successful, ListClients returns the clients and the total itemsPerPage of clients for
 // all pages. If unsuccessful, ListClients returns the error.
 func (a *API) ListClients(filter string, sortBy string, sortOrder SortOrder, startIndex int, itemsPerPage int) ([]Client, Page, error) {
+	return a.listClients(context.Background(), filter, sortBy, sortOrder, startIndex, itemsPerPage)
+}
+
+func (a *API) listClients(ctx context.Context, filter string, sortBy string, sortOrder SortOrder, startIndex int, itemsPerPage int) ([]Client, Page, error) {
 	u := urlWithPath(*a.TargetURL, ClientsEndpoint)
 	query := url.Values{}
 	if filter != "" {
@@ -95,7 +101,7 @@ func (a *API) ListClients(filter string, sortBy string, sortOrder SortOrder, sta
 	u.RawQuery = query.Encode()
 
 	clients := &paginatedClientList{}
-	err := a.doJSON(http.MethodGet, &u, nil, clients, true)
+	err := a.doJSONWithContext(ctx, http.MethodGet, &u, nil, clients, true)
 	if err != nil {
 		return nil, Page{}, err
 	}
@@ -107,8 +113,15 @@ func (a *API) ListClients(filter string, sortBy string, sortOrder SortOrder, sta
 	return clients.Resources, page, err
 }
 
-// ListAllClients retrieves UAA clients
+// ListAllClients retrieves UAA clients. If the API was constructed with
+// WithPageConcurrency, pages after the first are fetched in parallel.
 func (a *API) ListAllClients(filter string, sortBy string, sortOrder SortOrder) ([]Client, error) {
+	if a.pageConcurrency > 1 {
+		return listAllConcurrently(a.pageConcurrency, func(startIndex int, itemsPerPage int) ([]Client, Page, error) {
+			return a.ListClients(filter, sortBy, sortOrder, startIndex, itemsPerPage)
+		})
+	}
+
 	page := Page{
 		StartIndex:   1,
 		ItemsPerPage: 100,
@@ -133,3 +146,48 @@ func (a *API) ListAllClients(filter string, sortBy string, sortOrder SortOrder)
 	}
 	return results, nil
 }
+
+// Clients returns an iterator over the UAA clients that match the
+// given query. Pages are fetched lazily as the iterator is consumed, and no
+// further pages are fetched once the consumer stops iterating. If a page
+// cannot be fetched, the error is yielded and iteration ends.
+func (a *API) Clients(ctx context.Context, query ListQuery) iter.Seq2[Client, error] {
+	return func(yield func(Client, error) bool) {
+		page := Page{
+			StartIndex:   1,
+			ItemsPerPage: query.ItemsPerPage,
+		}
+		if page.ItemsPerPage == 0 {
+			page.ItemsPerPage = 100
+		}
+
+		for {
+			currentPage, p, err := a.listClients(ctx, query.Filter, query.SortBy, query.SortOrder, page.StartIndex, page.ItemsPerPage)
+			if err != nil {
+				yield(Client{}, err)
+				return
+			}
+			for _, item := range currentPage {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(currentPage) == 0 || (p.StartIndex+p.ItemsPerPage) > p.TotalResults {
+				return
+			}
+			page.StartIndex = p.StartIndex + p.ItemsPerPage
+			page.ItemsPerPage = p.ItemsPerPage
+		}
+	}
+}
+
+// MarshalJSON encodes the client, including its Extra members.
+func (client Client) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(client)
+}
+
+// UnmarshalJSON decodes the client, keeping unmodelled members in Extra.
+func (client *Client) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, client)
+}
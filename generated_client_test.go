@@ -3,9 +3,14 @@
 package uaa_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 
 	uaa "github.com/cloudfoundry-community/go-uaa"
@@ -158,6 +163,29 @@ func testClient(t *testing.T, when spec.G, it spec.S) {
 			Expect(updated).NotTo(BeNil())
 		})
 
+		it("sends back the members of the client that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testClientJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var client uaa.Client
+			Expect(json.Unmarshal(withUnmodelled, &client)).To(Succeed())
+			Expect(client.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(clientResponse))
+			})
+			_, err = a.UpdateClient(client)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		it("returns error when response cannot be parsed", func() {
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				Expect(req.Method).To(Equal(http.MethodPut))
@@ -267,6 +295,118 @@ func testClient(t *testing.T, when spec.G, it spec.S) {
 			Expect(clients).To(BeNil())
 			Expect(called).To(Equal(1))
 		})
+
+		when("page concurrency is enabled", func() {
+			it.Before(func() {
+				var err error
+				a, err = uaa.New(s.URL, uaa.WithNoAuthentication(), uaa.WithPageConcurrency(2))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("fetches the remaining pages in parallel and returns them in order", func() {
+				var mu sync.Mutex
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint))
+					mu.Lock()
+					defer mu.Unlock()
+					startIndex, err := strconv.Atoi(req.URL.Query().Get("startIndex"))
+					Expect(err).NotTo(HaveOccurred())
+					if startIndex == 1 {
+						Expect(req.URL.Query().Get("count")).To(Equal("100"))
+					} else {
+						Expect(req.URL.Query().Get("count")).To(Equal("1"))
+					}
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(MultiPaginatedResponse(startIndex, 1, 4, uaa.Client{ClientID: fmt.Sprintf("test-client-%d", startIndex)})))
+				})
+
+				clients, err := a.ListAllClients("", "", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(clients).To(HaveLen(4))
+				for i, client := range clients {
+					Expect(client.ClientID).To(Equal(fmt.Sprintf("test-client-%d", i+1)))
+				}
+				Expect(called).To(Equal(4))
+			})
+
+			it("returns an error when a later page fails", func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					if req.URL.Query().Get("startIndex") == "3" {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(MultiPaginatedResponse(1, 1, 3, uaa.Client{ClientID: "test-client"})))
+				})
+
+				clients, err := a.ListAllClients("", "", "")
+				Expect(err).To(HaveOccurred())
+				Expect(clients).To(BeNil())
+			})
+		})
+	})
+
+	when("Clients()", func() {
+		it.Before(func() {
+			page1 := MultiPaginatedResponse(1, 1, 2, uaa.Client{ClientID: "test-client-1"})
+			page2 := MultiPaginatedResponse(2, 1, 2, uaa.Client{ClientID: "test-client-2"})
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.ClientsEndpoint))
+				Expect(req.URL.Query().Get("filter")).To(Equal("id pr"))
+				w.WriteHeader(http.StatusOK)
+				if called == 1 {
+					Expect(req.URL.Query().Get("startIndex")).To(Equal("1"))
+					Expect(req.URL.Query().Get("count")).To(Equal("100"))
+					w.Write([]byte(page1))
+				} else {
+					Expect(req.URL.Query().Get("startIndex")).To(Equal("2"))
+					Expect(req.URL.Query().Get("count")).To(Equal("1"))
+					w.Write([]byte(page2))
+				}
+			})
+		})
+
+		it("fetches pages lazily as the iterator is consumed", func() {
+			var ids []string
+			for client, err := range a.Clients(context.Background(), uaa.ListQuery{Filter: "id pr"}) {
+				Expect(err).NotTo(HaveOccurred())
+				ids = append(ids, client.ClientID)
+			}
+			Expect(ids).To(Equal([]string{"test-client-1", "test-client-2"}))
+			Expect(called).To(Equal(2))
+		})
+
+		it("stops fetching once the consumer stops iterating", func() {
+			for client, err := range a.Clients(context.Background(), uaa.ListQuery{Filter: "id pr"}) {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.ClientID).To(Equal("test-client-1"))
+				break
+			}
+			Expect(called).To(Equal(1))
+		})
+
+		it("yields an error when a page cannot be fetched", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			var errs []error
+			for _, err := range a.Clients(context.Background(), uaa.ListQuery{}) {
+				errs = append(errs, err)
+			}
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("does not make a request when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			for _, err := range a.Clients(ctx, uaa.ListQuery{}) {
+				Expect(err).To(HaveOccurred())
+			}
+			Expect(called).To(Equal(0))
+		})
 	})
 
 	when("ListClients()", func() {
@@ -4,9 +4,11 @@ package uaa
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -47,7 +49,7 @@ func (a *API) UpdateGroup(group Group) (*Group, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = a.doJSON(http.MethodPut, &u, bytes.NewBuffer([]byte(j)), created, true)
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +75,10 @@ func (a *API) DeleteGroup(groupID string) (*Group, error) {
 // If successful, ListGroups returns the groups and the total itemsPerPage of groups for
 // all pages. If unsuccessful, ListGroups returns the error.
 func (a *API) ListGroups(filter string, sortBy string, attributes string, sortOrder SortOrder, startIndex int, itemsPerPage int) ([]Group, Page, error) {
+	return a.listGroups(context.Background(), filter, sortBy, attributes, sortOrder, startIndex, itemsPerPage)
+}
+
+func (a *API) listGroups(ctx context.Context, filter string, sortBy string, attributes string, sortOrder SortOrder, startIndex int, itemsPerPage int) ([]Group, Page, error) {
 	u := urlWithPath(*a.TargetURL, GroupsEndpoint)
 	query := url.Values{}
 	if filter != "" {
@@ -98,7 +104,7 @@ func (a *API) ListGroups(filter string, sortBy string, attributes string, sortOr
 	u.RawQuery = query.Encode()
 
 	groups := &paginatedGroupList{}
-	err := a.doJSON(http.MethodGet, &u, nil, groups, true)
+	err := a.doJSONWithContext(ctx, http.MethodGet, &u, nil, groups, true)
 	if err != nil {
 		return nil, Page{}, err
 	}
@@ -110,8 +116,15 @@ func (a *API) ListGroups(filter string, sortBy string, attributes string, sortOr
 	return groups.Resources, page, err
 }
 
-// ListAllGroups retrieves UAA groups
+// ListAllGroups retrieves UAA groups. If the API was constructed with
+// WithPageConcurrency, pages after the first are fetched in parallel.
 func (a *API) ListAllGroups(filter string, sortBy string, attributes string, sortOrder SortOrder) ([]Group, error) {
+	if a.pageConcurrency > 1 {
+		return listAllConcurrently(a.pageConcurrency, func(startIndex int, itemsPerPage int) ([]Group, Page, error) {
+			return a.ListGroups(filter, sortBy, attributes, sortOrder, startIndex, itemsPerPage)
+		})
+	}
+
 	page := Page{
 		StartIndex:   1,
 		ItemsPerPage: 100,
@@ -136,3 +149,48 @@ func (a *API) ListAllGroups(filter string, sortBy string, attributes string, sor
 	}
 	return results, nil
 }
+
+// Groups returns an iterator over the UAA groups that match the
+// given query. Pages are fetched lazily as the iterator is consumed, and no
+// further pages are fetched once the consumer stops iterating. If a page
+// cannot be fetched, the error is yielded and iteration ends.
+func (a *API) Groups(ctx context.Context, query ListQuery) iter.Seq2[Group, error] {
+	return func(yield func(Group, error) bool) {
+		page := Page{
+			StartIndex:   1,
+			ItemsPerPage: query.ItemsPerPage,
+		}
+		if page.ItemsPerPage == 0 {
+			page.ItemsPerPage = 100
+		}
+
+		for {
+			currentPage, p, err := a.listGroups(ctx, query.Filter, query.SortBy, query.Attributes, query.SortOrder, page.StartIndex, page.ItemsPerPage)
+			if err != nil {
+				yield(Group{}, err)
+				return
+			}
+			for _, item := range currentPage {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(currentPage) == 0 || (p.StartIndex+p.ItemsPerPage) > p.TotalResults {
+				return
+			}
+			page.StartIndex = p.StartIndex + p.ItemsPerPage
+			page.ItemsPerPage = p.ItemsPerPage
+		}
+	}
+}
+
+// MarshalJSON encodes the group, including its Extra members.
+func (group Group) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(group)
+}
+
+// UnmarshalJSON decodes the group, keeping unmodelled members in Extra.
+func (group *Group) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, group)
+}
@@ -3,9 +3,14 @@
 package uaa_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 
 	uaa "github.com/cloudfoundry-community/go-uaa"
@@ -158,6 +163,29 @@ func testGroup(t *testing.T, when spec.G, it spec.S) {
 			Expect(updated).NotTo(BeNil())
 		})
 
+		it("sends back the members of the group that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testGroupJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var group uaa.Group
+			Expect(json.Unmarshal(withUnmodelled, &group)).To(Succeed())
+			Expect(group.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(groupResponse))
+			})
+			_, err = a.UpdateGroup(group)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		it("returns error when response cannot be parsed", func() {
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				Expect(req.Method).To(Equal(http.MethodPut))
@@ -267,6 +295,118 @@ func testGroup(t *testing.T, when spec.G, it spec.S) {
 			Expect(groups).To(BeNil())
 			Expect(called).To(Equal(1))
 		})
+
+		when("page concurrency is enabled", func() {
+			it.Before(func() {
+				var err error
+				a, err = uaa.New(s.URL, uaa.WithNoAuthentication(), uaa.WithPageConcurrency(2))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("fetches the remaining pages in parallel and returns them in order", func() {
+				var mu sync.Mutex
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint))
+					mu.Lock()
+					defer mu.Unlock()
+					startIndex, err := strconv.Atoi(req.URL.Query().Get("startIndex"))
+					Expect(err).NotTo(HaveOccurred())
+					if startIndex == 1 {
+						Expect(req.URL.Query().Get("count")).To(Equal("100"))
+					} else {
+						Expect(req.URL.Query().Get("count")).To(Equal("1"))
+					}
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(MultiPaginatedResponse(startIndex, 1, 4, uaa.Group{ID: fmt.Sprintf("test-group-%d", startIndex)})))
+				})
+
+				groups, err := a.ListAllGroups("", "", "", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groups).To(HaveLen(4))
+				for i, group := range groups {
+					Expect(group.ID).To(Equal(fmt.Sprintf("test-group-%d", i+1)))
+				}
+				Expect(called).To(Equal(4))
+			})
+
+			it("returns an error when a later page fails", func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					if req.URL.Query().Get("startIndex") == "3" {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(MultiPaginatedResponse(1, 1, 3, uaa.Group{ID: "test-group"})))
+				})
+
+				groups, err := a.ListAllGroups("", "", "", "")
+				Expect(err).To(HaveOccurred())
+				Expect(groups).To(BeNil())
+			})
+		})
+	})
+
+	when("Groups()", func() {
+		it.Before(func() {
+			page1 := MultiPaginatedResponse(1, 1, 2, uaa.Group{ID: "test-group-1"})
+			page2 := MultiPaginatedResponse(2, 1, 2, uaa.Group{ID: "test-group-2"})
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint))
+				Expect(req.URL.Query().Get("filter")).To(Equal("id pr"))
+				w.WriteHeader(http.StatusOK)
+				if called == 1 {
+					Expect(req.URL.Query().Get("startIndex")).To(Equal("1"))
+					Expect(req.URL.Query().Get("count")).To(Equal("100"))
+					w.Write([]byte(page1))
+				} else {
+					Expect(req.URL.Query().Get("startIndex")).To(Equal("2"))
+					Expect(req.URL.Query().Get("count")).To(Equal("1"))
+					w.Write([]byte(page2))
+				}
+			})
+		})
+
+		it("fetches pages lazily as the iterator is consumed", func() {
+			var ids []string
+			for group, err := range a.Groups(context.Background(), uaa.ListQuery{Filter: "id pr"}) {
+				Expect(err).NotTo(HaveOccurred())
+				ids = append(ids, group.ID)
+			}
+			Expect(ids).To(Equal([]string{"test-group-1", "test-group-2"}))
+			Expect(called).To(Equal(2))
+		})
+
+		it("stops fetching once the consumer stops iterating", func() {
+			for group, err := range a.Groups(context.Background(), uaa.ListQuery{Filter: "id pr"}) {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(group.ID).To(Equal("test-group-1"))
+				break
+			}
+			Expect(called).To(Equal(1))
+		})
+
+		it("yields an error when a page cannot be fetched", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			var errs []error
+			for _, err := range a.Groups(context.Background(), uaa.ListQuery{}) {
+				errs = append(errs, err)
+			}
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("does not make a request when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			for _, err := range a.Groups(ctx, uaa.ListQuery{}) {
+				Expect(err).To(HaveOccurred())
+			}
+			Expect(called).To(Equal(0))
+		})
 	})
 
 	when("ListGroups()", func() {
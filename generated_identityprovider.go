@@ -0,0 +1,80 @@
+// Code generated by go-uaa/generator; DO NOT EDIT.
+
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// GetIdentityProvider with the given identityproviderID.
+func (a *API) GetIdentityProvider(identityproviderID string) (*IdentityProvider, error) {
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", IdentityProvidersEndpoint, identityproviderID))
+	identityprovider := &IdentityProvider{}
+	err := a.doJSON(http.MethodGet, &u, nil, identityprovider, true)
+	if err != nil {
+		return nil, err
+	}
+	return identityprovider, nil
+}
+
+// CreateIdentityProvider creates the given identityprovider.
+func (a *API) CreateIdentityProvider(identityprovider IdentityProvider) (*IdentityProvider, error) {
+	u := urlWithPath(*a.TargetURL, IdentityProvidersEndpoint)
+	created := &IdentityProvider{}
+	j, err := json.Marshal(identityprovider)
+	if err != nil {
+		return nil, err
+	}
+	err = a.doJSON(http.MethodPost, &u, bytes.NewBuffer([]byte(j)), created, true)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateIdentityProvider updates the given identityprovider.
+func (a *API) UpdateIdentityProvider(identityprovider IdentityProvider) (*IdentityProvider, error) {
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", IdentityProvidersEndpoint, identityprovider.Identifier()))
+
+	created := &IdentityProvider{}
+	j, err := json.Marshal(identityprovider)
+	if err != nil {
+		return nil, err
+	}
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteIdentityProvider deletes the identityprovider with the given identityprovider ID.
+func (a *API) DeleteIdentityProvider(identityproviderID string) (*IdentityProvider, error) {
+	if identityproviderID == "" {
+		return nil, errors.New("identityproviderID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", IdentityProvidersEndpoint, identityproviderID))
+	deleted := &IdentityProvider{}
+	err := a.doJSON(http.MethodDelete, &u, nil, deleted, true)
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ListIdentityProviders fetches all of the IdentityProvider records.
+// If successful, ListIdentityProviders returns the identityproviders
+// If unsuccessful, ListIdentityProviders returns the error.
+func (a *API) ListIdentityProviders() ([]IdentityProvider, error) {
+	u := urlWithPath(*a.TargetURL, IdentityProvidersEndpoint)
+	var identityproviders []IdentityProvider
+	err := a.doJSON(http.MethodGet, &u, nil, &identityproviders, true)
+	if err != nil {
+		return nil, err
+	}
+	return identityproviders, nil
+}
@@ -0,0 +1,294 @@
+// Code generated by go-uaa/generator; DO NOT EDIT.
+
+package uaa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testIdentityProvider(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		var err error
+		a, err = uaa.New(s.URL, uaa.WithNoAuthentication())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("GetIdentityProvider()", func() {
+		when("the identityprovider is returned from the server", func() {
+			it.Before(func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+					Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(identityproviderResponse))
+				})
+			})
+			it("gets the identityprovider from the UAA by ID", func() {
+				identityprovider, err := a.GetIdentityProvider("00000000-0000-0000-0000-000000000001")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(identityprovider.ID).To(Equal("00000000-0000-0000-0000-000000000001"))
+			})
+		})
+
+		when("the server errors", func() {
+			it.Before(func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+					Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+					w.WriteHeader(http.StatusInternalServerError)
+				})
+			})
+
+			it("returns helpful error", func() {
+				identityprovider, err := a.GetIdentityProvider("00000000-0000-0000-0000-000000000001")
+				Expect(err).To(HaveOccurred())
+				Expect(identityprovider).To(BeNil())
+				Expect(err.Error()).To(ContainSubstring("An error occurred while calling"))
+			})
+		})
+
+		when("the server returns unparsable identityproviders", func() {
+			it.Before(func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+					Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte("{unparsable-json-response}"))
+				})
+			})
+
+			it("returns helpful error", func() {
+				identityprovider, err := a.GetIdentityProvider("00000000-0000-0000-0000-000000000001")
+				Expect(err).To(HaveOccurred())
+				Expect(identityprovider).To(BeNil())
+				Expect(err.Error()).To(ContainSubstring("An unknown error occurred while parsing response from"))
+				Expect(err.Error()).To(ContainSubstring("Response was {unparsable-json-response}"))
+			})
+		})
+	})
+
+	when("CreateIdentityProvider()", func() {
+		it("performs a POST with the identityprovider data and returns the created identityprovider", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(testIdentityProviderJSON))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(identityproviderResponse))
+			})
+
+			created, err := a.CreateIdentityProvider(testIdentityProviderValue)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).NotTo(BeNil())
+		})
+
+		it("returns error when response cannot be parsed", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparseable}"))
+			})
+			created, err := a.CreateIdentityProvider(testIdentityProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(created).To(BeNil())
+		})
+
+		it("returns error when response is not 200 OK", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint))
+				w.WriteHeader(http.StatusBadRequest)
+			})
+			created, err := a.CreateIdentityProvider(testIdentityProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(created).To(BeNil())
+		})
+	})
+
+	when("UpdateIdentityProvider()", func() {
+		it("performs a PUT with the identityprovider data and returns the updated identityprovider", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(testIdentityProviderJSON))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(identityproviderResponse))
+			})
+
+			updated, err := a.UpdateIdentityProvider(testIdentityProviderValue)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).NotTo(BeNil())
+		})
+
+		it("sends back the members of the identityprovider that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testIdentityProviderJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var identityprovider uaa.IdentityProvider
+			Expect(json.Unmarshal(withUnmodelled, &identityprovider)).To(Succeed())
+			Expect(identityprovider.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(identityproviderResponse))
+			})
+			_, err = a.UpdateIdentityProvider(identityprovider)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("returns error when response cannot be parsed", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparseable}"))
+			})
+			updated, err := a.UpdateIdentityProvider(testIdentityProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(updated).To(BeNil())
+		})
+
+		it("returns error when response is not 200 OK", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusBadRequest)
+			})
+			updated, err := a.UpdateIdentityProvider(testIdentityProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(updated).To(BeNil())
+		})
+	})
+
+	when("DeleteIdentityProvider()", func() {
+		it("errors when the identityproviderID is empty", func() {
+			deleted, err := a.DeleteIdentityProvider("")
+			Expect(called).To(Equal(0))
+			Expect(err).To(HaveOccurred())
+			Expect(deleted).To(BeNil())
+		})
+
+		it("performs a DELETE for the identityprovider", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(identityproviderResponse))
+			})
+
+			deleted, err := a.DeleteIdentityProvider("00000000-0000-0000-0000-000000000001")
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).NotTo(BeNil())
+		})
+
+		it("returns error when response cannot be parsed", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparseable}"))
+			})
+			deleted, err := a.DeleteIdentityProvider("00000000-0000-0000-0000-000000000001")
+			Expect(err).To(HaveOccurred())
+			Expect(deleted).To(BeNil())
+		})
+
+		it("returns error when response is not 200 OK", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusBadRequest)
+			})
+			deleted, err := a.DeleteIdentityProvider("00000000-0000-0000-0000-000000000001")
+			Expect(err).To(HaveOccurred())
+			Expect(deleted).To(BeNil())
+		})
+	})
+
+	when("ListIdentityProviders()", func() {
+		it("can accept a filter query to limit results", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(identityproviderListResponse))
+			})
+			identityproviderList, err := a.ListIdentityProviders()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(identityproviderList[0].ID).To(Equal("00000000-0000-0000-0000-000000000001"))
+			Expect(identityproviderList[1].ID).To(Equal("00000000-0000-0000-0000-000000000002"))
+		})
+
+		it("returns an error when the endpoint doesn't respond", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint))
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			identityproviderList, err := a.ListIdentityProviders()
+			Expect(err).To(HaveOccurred())
+			Expect(identityproviderList).To(BeNil())
+		})
+
+		it("returns an error when response is unparseable", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparsable}"))
+			})
+			identityproviderList, err := a.ListIdentityProviders()
+			Expect(err).To(HaveOccurred())
+			Expect(identityproviderList).To(BeNil())
+		})
+	})
+}
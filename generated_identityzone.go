@@ -45,7 +45,7 @@ func (a *API) UpdateIdentityZone(identityzone IdentityZone) (*IdentityZone, erro
 	if err != nil {
 		return nil, err
 	}
-	err = a.doJSON(http.MethodPut, &u, bytes.NewBuffer([]byte(j)), created, true)
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
 	if err != nil {
 		return nil, err
 	}
@@ -78,3 +78,13 @@ func (a *API) ListIdentityZones() ([]IdentityZone, error) {
 	}
 	return identityzones, nil
 }
+
+// MarshalJSON encodes the identityzone, including its Extra members.
+func (identityzone IdentityZone) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(identityzone)
+}
+
+// UnmarshalJSON decodes the identityzone, keeping unmodelled members in Extra.
+func (identityzone *IdentityZone) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, identityzone)
+}
@@ -3,6 +3,7 @@
 package uaa_test
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -158,6 +159,29 @@ func testIdentityZone(t *testing.T, when spec.G, it spec.S) {
 			Expect(updated).NotTo(BeNil())
 		})
 
+		it("sends back the members of the identityzone that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testIdentityZoneJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var identityzone uaa.IdentityZone
+			Expect(json.Unmarshal(withUnmodelled, &identityzone)).To(Succeed())
+			Expect(identityzone.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(identityzoneResponse))
+			})
+			_, err = a.UpdateIdentityZone(identityzone)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		it("returns error when response cannot be parsed", func() {
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				Expect(req.Method).To(Equal(http.MethodPut))
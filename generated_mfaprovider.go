@@ -21,8 +21,12 @@ func (a *API) GetMFAProvider(mfaproviderID string) (*MFAProvider, error) {
 	return mfaprovider, nil
 }
 
-// CreateMFAProvider creates the given mfaprovider.
+// CreateMFAProvider creates the given mfaprovider. It returns the
+// problems Validate finds, without calling UAA, when the mfaprovider is invalid.
 func (a *API) CreateMFAProvider(mfaprovider MFAProvider) (*MFAProvider, error) {
+	if err := mfaprovider.Validate(); err != nil {
+		return nil, err
+	}
 	u := urlWithPath(*a.TargetURL, MFAProvidersEndpoint)
 	created := &MFAProvider{}
 	j, err := json.Marshal(mfaprovider)
@@ -45,7 +49,7 @@ func (a *API) UpdateMFAProvider(mfaprovider MFAProvider) (*MFAProvider, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = a.doJSON(http.MethodPut, &u, bytes.NewBuffer([]byte(j)), created, true)
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
 	if err != nil {
 		return nil, err
 	}
@@ -78,3 +82,13 @@ func (a *API) ListMFAProviders() ([]MFAProvider, error) {
 	}
 	return mfaproviders, nil
 }
+
+// MarshalJSON encodes the mfaprovider, including its Extra members.
+func (mfaprovider MFAProvider) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(mfaprovider)
+}
+
+// UnmarshalJSON decodes the mfaprovider, keeping unmodelled members in Extra.
+func (mfaprovider *MFAProvider) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, mfaprovider)
+}
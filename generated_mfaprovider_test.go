@@ -3,6 +3,7 @@
 package uaa_test
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -95,6 +96,13 @@ func testMFAProvider(t *testing.T, when spec.G, it spec.S) {
 	})
 
 	when("CreateMFAProvider()", func() {
+		it("validates the mfaprovider before calling UAA", func() {
+			created, err := a.CreateMFAProvider(uaa.MFAProvider{})
+			Expect(called).To(Equal(0))
+			Expect(err).To(HaveOccurred())
+			Expect(created).To(BeNil())
+		})
+
 		it("performs a POST with the mfaprovider data and returns the created mfaprovider", func() {
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
@@ -158,6 +166,29 @@ func testMFAProvider(t *testing.T, when spec.G, it spec.S) {
 			Expect(updated).NotTo(BeNil())
 		})
 
+		it("sends back the members of the mfaprovider that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testMFAProviderJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var mfaprovider uaa.MFAProvider
+			Expect(json.Unmarshal(withUnmodelled, &mfaprovider)).To(Succeed())
+			Expect(mfaprovider.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(mfaproviderResponse))
+			})
+			_, err = a.UpdateMFAProvider(mfaprovider)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		it("returns error when response cannot be parsed", func() {
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				Expect(req.Method).To(Equal(http.MethodPut))
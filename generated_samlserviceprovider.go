@@ -0,0 +1,90 @@
+// Code generated by go-uaa/generator; DO NOT EDIT.
+
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// GetSAMLServiceProvider with the given samlserviceproviderID.
+func (a *API) GetSAMLServiceProvider(samlserviceproviderID string) (*SAMLServiceProvider, error) {
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", SAMLServiceProvidersEndpoint, samlserviceproviderID))
+	samlserviceprovider := &SAMLServiceProvider{}
+	err := a.doJSON(http.MethodGet, &u, nil, samlserviceprovider, true)
+	if err != nil {
+		return nil, err
+	}
+	return samlserviceprovider, nil
+}
+
+// CreateSAMLServiceProvider creates the given samlserviceprovider.
+func (a *API) CreateSAMLServiceProvider(samlserviceprovider SAMLServiceProvider) (*SAMLServiceProvider, error) {
+	u := urlWithPath(*a.TargetURL, SAMLServiceProvidersEndpoint)
+	created := &SAMLServiceProvider{}
+	j, err := json.Marshal(samlserviceprovider)
+	if err != nil {
+		return nil, err
+	}
+	err = a.doJSON(http.MethodPost, &u, bytes.NewBuffer([]byte(j)), created, true)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateSAMLServiceProvider updates the given samlserviceprovider.
+func (a *API) UpdateSAMLServiceProvider(samlserviceprovider SAMLServiceProvider) (*SAMLServiceProvider, error) {
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", SAMLServiceProvidersEndpoint, samlserviceprovider.Identifier()))
+
+	created := &SAMLServiceProvider{}
+	j, err := json.Marshal(samlserviceprovider)
+	if err != nil {
+		return nil, err
+	}
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteSAMLServiceProvider deletes the samlserviceprovider with the given samlserviceprovider ID.
+func (a *API) DeleteSAMLServiceProvider(samlserviceproviderID string) (*SAMLServiceProvider, error) {
+	if samlserviceproviderID == "" {
+		return nil, errors.New("samlserviceproviderID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s", SAMLServiceProvidersEndpoint, samlserviceproviderID))
+	deleted := &SAMLServiceProvider{}
+	err := a.doJSON(http.MethodDelete, &u, nil, deleted, true)
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// ListSAMLServiceProviders fetches all of the SAMLServiceProvider records.
+// If successful, ListSAMLServiceProviders returns the samlserviceproviders
+// If unsuccessful, ListSAMLServiceProviders returns the error.
+func (a *API) ListSAMLServiceProviders() ([]SAMLServiceProvider, error) {
+	u := urlWithPath(*a.TargetURL, SAMLServiceProvidersEndpoint)
+	var samlserviceproviders []SAMLServiceProvider
+	err := a.doJSON(http.MethodGet, &u, nil, &samlserviceproviders, true)
+	if err != nil {
+		return nil, err
+	}
+	return samlserviceproviders, nil
+}
+
+// MarshalJSON encodes the samlserviceprovider, including its Extra members.
+func (samlserviceprovider SAMLServiceProvider) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(samlserviceprovider)
+}
+
+// UnmarshalJSON decodes the samlserviceprovider, keeping unmodelled members in Extra.
+func (samlserviceprovider *SAMLServiceProvider) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, samlserviceprovider)
+}
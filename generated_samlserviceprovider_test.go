@@ -0,0 +1,294 @@
+// Code generated by go-uaa/generator; DO NOT EDIT.
+
+package uaa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testSAMLServiceProvider(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		var err error
+		a, err = uaa.New(s.URL, uaa.WithNoAuthentication())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("GetSAMLServiceProvider()", func() {
+		when("the samlserviceprovider is returned from the server", func() {
+			it.Before(func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+					Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(samlserviceproviderResponse))
+				})
+			})
+			it("gets the samlserviceprovider from the UAA by ID", func() {
+				samlserviceprovider, err := a.GetSAMLServiceProvider("00000000-0000-0000-0000-000000000001")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(samlserviceprovider.ID).To(Equal("00000000-0000-0000-0000-000000000001"))
+			})
+		})
+
+		when("the server errors", func() {
+			it.Before(func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+					Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+					w.WriteHeader(http.StatusInternalServerError)
+				})
+			})
+
+			it("returns helpful error", func() {
+				samlserviceprovider, err := a.GetSAMLServiceProvider("00000000-0000-0000-0000-000000000001")
+				Expect(err).To(HaveOccurred())
+				Expect(samlserviceprovider).To(BeNil())
+				Expect(err.Error()).To(ContainSubstring("An error occurred while calling"))
+			})
+		})
+
+		when("the server returns unparsable samlserviceproviders", func() {
+			it.Before(func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+					Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte("{unparsable-json-response}"))
+				})
+			})
+
+			it("returns helpful error", func() {
+				samlserviceprovider, err := a.GetSAMLServiceProvider("00000000-0000-0000-0000-000000000001")
+				Expect(err).To(HaveOccurred())
+				Expect(samlserviceprovider).To(BeNil())
+				Expect(err.Error()).To(ContainSubstring("An unknown error occurred while parsing response from"))
+				Expect(err.Error()).To(ContainSubstring("Response was {unparsable-json-response}"))
+			})
+		})
+	})
+
+	when("CreateSAMLServiceProvider()", func() {
+		it("performs a POST with the samlserviceprovider data and returns the created samlserviceprovider", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(testSAMLServiceProviderJSON))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(samlserviceproviderResponse))
+			})
+
+			created, err := a.CreateSAMLServiceProvider(testSAMLServiceProviderValue)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).NotTo(BeNil())
+		})
+
+		it("returns error when response cannot be parsed", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparseable}"))
+			})
+			created, err := a.CreateSAMLServiceProvider(testSAMLServiceProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(created).To(BeNil())
+		})
+
+		it("returns error when response is not 200 OK", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				w.WriteHeader(http.StatusBadRequest)
+			})
+			created, err := a.CreateSAMLServiceProvider(testSAMLServiceProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(created).To(BeNil())
+		})
+	})
+
+	when("UpdateSAMLServiceProvider()", func() {
+		it("performs a PUT with the samlserviceprovider data and returns the updated samlserviceprovider", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(testSAMLServiceProviderJSON))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(samlserviceproviderResponse))
+			})
+
+			updated, err := a.UpdateSAMLServiceProvider(testSAMLServiceProviderValue)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).NotTo(BeNil())
+		})
+
+		it("sends back the members of the samlserviceprovider that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testSAMLServiceProviderJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var samlserviceprovider uaa.SAMLServiceProvider
+			Expect(json.Unmarshal(withUnmodelled, &samlserviceprovider)).To(Succeed())
+			Expect(samlserviceprovider.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(samlserviceproviderResponse))
+			})
+			_, err = a.UpdateSAMLServiceProvider(samlserviceprovider)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("returns error when response cannot be parsed", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparseable}"))
+			})
+			updated, err := a.UpdateSAMLServiceProvider(testSAMLServiceProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(updated).To(BeNil())
+		})
+
+		it("returns error when response is not 200 OK", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusBadRequest)
+			})
+			updated, err := a.UpdateSAMLServiceProvider(testSAMLServiceProviderValue)
+			Expect(err).To(HaveOccurred())
+			Expect(updated).To(BeNil())
+		})
+	})
+
+	when("DeleteSAMLServiceProvider()", func() {
+		it("errors when the samlserviceproviderID is empty", func() {
+			deleted, err := a.DeleteSAMLServiceProvider("")
+			Expect(called).To(Equal(0))
+			Expect(err).To(HaveOccurred())
+			Expect(deleted).To(BeNil())
+		})
+
+		it("performs a DELETE for the samlserviceprovider", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(samlserviceproviderResponse))
+			})
+
+			deleted, err := a.DeleteSAMLServiceProvider("00000000-0000-0000-0000-000000000001")
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).NotTo(BeNil())
+		})
+
+		it("returns error when response cannot be parsed", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparseable}"))
+			})
+			deleted, err := a.DeleteSAMLServiceProvider("00000000-0000-0000-0000-000000000001")
+			Expect(err).To(HaveOccurred())
+			Expect(deleted).To(BeNil())
+		})
+
+		it("returns error when response is not 200 OK", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint + "/00000000-0000-0000-0000-000000000001"))
+				w.WriteHeader(http.StatusBadRequest)
+			})
+			deleted, err := a.DeleteSAMLServiceProvider("00000000-0000-0000-0000-000000000001")
+			Expect(err).To(HaveOccurred())
+			Expect(deleted).To(BeNil())
+		})
+	})
+
+	when("ListSAMLServiceProviders()", func() {
+		it("can accept a filter query to limit results", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(samlserviceproviderListResponse))
+			})
+			samlserviceproviderList, err := a.ListSAMLServiceProviders()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samlserviceproviderList[0].ID).To(Equal("00000000-0000-0000-0000-000000000001"))
+			Expect(samlserviceproviderList[1].ID).To(Equal("00000000-0000-0000-0000-000000000002"))
+		})
+
+		it("returns an error when the endpoint doesn't respond", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			samlserviceproviderList, err := a.ListSAMLServiceProviders()
+			Expect(err).To(HaveOccurred())
+			Expect(samlserviceproviderList).To(BeNil())
+		})
+
+		it("returns an error when response is unparseable", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{unparsable}"))
+			})
+			samlserviceproviderList, err := a.ListSAMLServiceProviders()
+			Expect(err).To(HaveOccurred())
+			Expect(samlserviceproviderList).To(BeNil())
+		})
+	})
+}
@@ -4,9 +4,11 @@ package uaa
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -47,7 +49,7 @@ func (a *API) UpdateUser(user User) (*User, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = a.doJSON(http.MethodPut, &u, bytes.NewBuffer([]byte(j)), created, true)
+	err = a.doJSONWithHeaders(http.MethodPut, &u, map[string]string{"If-Match": "*"}, bytes.NewBuffer([]byte(j)), created, true)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +75,10 @@ func (a *API) DeleteUser(userID string) (*User, error) {
 // If successful, ListUsers returns the users and the total itemsPerPage of users for
 // all pages. If unsuccessful, ListUsers returns the error.
 func (a *API) ListUsers(filter string, sortBy string, attributes string, sortOrder SortOrder, startIndex int, itemsPerPage int) ([]User, Page, error) {
+	return a.listUsers(context.Background(), filter, sortBy, attributes, sortOrder, startIndex, itemsPerPage)
+}
+
+func (a *API) listUsers(ctx context.Context, filter string, sortBy string, attributes string, sortOrder SortOrder, startIndex int, itemsPerPage int) ([]User, Page, error) {
 	u := urlWithPath(*a.TargetURL, UsersEndpoint)
 	query := url.Values{}
 	if filter != "" {
@@ -98,7 +104,7 @@ func (a *API) ListUsers(filter string, sortBy string, attributes string, sortOrd
 	u.RawQuery = query.Encode()
 
 	users := &paginatedUserList{}
-	err := a.doJSON(http.MethodGet, &u, nil, users, true)
+	err := a.doJSONWithContext(ctx, http.MethodGet, &u, nil, users, true)
 	if err != nil {
 		return nil, Page{}, err
 	}
@@ -110,8 +116,15 @@ func (a *API) ListUsers(filter string, sortBy string, attributes string, sortOrd
 	return users.Resources, page, err
 }
 
-// ListAllUsers retrieves UAA users
+// ListAllUsers retrieves UAA users. If the API was constructed with
+// WithPageConcurrency, pages after the first are fetched in parallel.
 func (a *API) ListAllUsers(filter string, sortBy string, attributes string, sortOrder SortOrder) ([]User, error) {
+	if a.pageConcurrency > 1 {
+		return listAllConcurrently(a.pageConcurrency, func(startIndex int, itemsPerPage int) ([]User, Page, error) {
+			return a.ListUsers(filter, sortBy, attributes, sortOrder, startIndex, itemsPerPage)
+		})
+	}
+
 	page := Page{
 		StartIndex:   1,
 		ItemsPerPage: 100,
@@ -136,3 +149,48 @@ func (a *API) ListAllUsers(filter string, sortBy string, attributes string, sort
 	}
 	return results, nil
 }
+
+// Users returns an iterator over the UAA users that match the
+// given query. Pages are fetched lazily as the iterator is consumed, and no
+// further pages are fetched once the consumer stops iterating. If a page
+// cannot be fetched, the error is yielded and iteration ends.
+func (a *API) Users(ctx context.Context, query ListQuery) iter.Seq2[User, error] {
+	return func(yield func(User, error) bool) {
+		page := Page{
+			StartIndex:   1,
+			ItemsPerPage: query.ItemsPerPage,
+		}
+		if page.ItemsPerPage == 0 {
+			page.ItemsPerPage = 100
+		}
+
+		for {
+			currentPage, p, err := a.listUsers(ctx, query.Filter, query.SortBy, query.Attributes, query.SortOrder, page.StartIndex, page.ItemsPerPage)
+			if err != nil {
+				yield(User{}, err)
+				return
+			}
+			for _, item := range currentPage {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(currentPage) == 0 || (p.StartIndex+p.ItemsPerPage) > p.TotalResults {
+				return
+			}
+			page.StartIndex = p.StartIndex + p.ItemsPerPage
+			page.ItemsPerPage = p.ItemsPerPage
+		}
+	}
+}
+
+// MarshalJSON encodes the user, including its Extra members.
+func (user User) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(user)
+}
+
+// UnmarshalJSON decodes the user, keeping unmodelled members in Extra.
+func (user *User) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, user)
+}
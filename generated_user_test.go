@@ -3,9 +3,14 @@
 package uaa_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 
 	uaa "github.com/cloudfoundry-community/go-uaa"
@@ -158,6 +163,29 @@ func testUser(t *testing.T, when spec.G, it spec.S) {
 			Expect(updated).NotTo(BeNil())
 		})
 
+		it("sends back the members of the user that it does not model", func() {
+			var members map[string]json.RawMessage
+			Expect(json.Unmarshal([]byte(testUserJSON), &members)).To(Succeed())
+			members["unmodelledMember"] = json.RawMessage(`{"added":[1,2]}`)
+			withUnmodelled, err := json.Marshal(members)
+			Expect(err).NotTo(HaveOccurred())
+			var user uaa.User
+			Expect(json.Unmarshal(withUnmodelled, &user)).To(Succeed())
+			Expect(user.Extra).To(HaveKey("unmodelledMember"))
+
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(withUnmodelled))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(userResponse))
+			})
+			_, err = a.UpdateUser(user)
+			Expect(called).To(Equal(1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		it("returns error when response cannot be parsed", func() {
 			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 				Expect(req.Method).To(Equal(http.MethodPut))
@@ -267,6 +295,118 @@ func testUser(t *testing.T, when spec.G, it spec.S) {
 			Expect(users).To(BeNil())
 			Expect(called).To(Equal(1))
 		})
+
+		when("page concurrency is enabled", func() {
+			it.Before(func() {
+				var err error
+				a, err = uaa.New(s.URL, uaa.WithNoAuthentication(), uaa.WithPageConcurrency(2))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			it("fetches the remaining pages in parallel and returns them in order", func() {
+				var mu sync.Mutex
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.URL.Path).To(Equal(uaa.UsersEndpoint))
+					mu.Lock()
+					defer mu.Unlock()
+					startIndex, err := strconv.Atoi(req.URL.Query().Get("startIndex"))
+					Expect(err).NotTo(HaveOccurred())
+					if startIndex == 1 {
+						Expect(req.URL.Query().Get("count")).To(Equal("100"))
+					} else {
+						Expect(req.URL.Query().Get("count")).To(Equal("1"))
+					}
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(MultiPaginatedResponse(startIndex, 1, 4, uaa.User{ID: fmt.Sprintf("test-user-%d", startIndex)})))
+				})
+
+				users, err := a.ListAllUsers("", "", "", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(users).To(HaveLen(4))
+				for i, user := range users {
+					Expect(user.ID).To(Equal(fmt.Sprintf("test-user-%d", i+1)))
+				}
+				Expect(called).To(Equal(4))
+			})
+
+			it("returns an error when a later page fails", func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					if req.URL.Query().Get("startIndex") == "3" {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(MultiPaginatedResponse(1, 1, 3, uaa.User{ID: "test-user"})))
+				})
+
+				users, err := a.ListAllUsers("", "", "", "")
+				Expect(err).To(HaveOccurred())
+				Expect(users).To(BeNil())
+			})
+		})
+	})
+
+	when("Users()", func() {
+		it.Before(func() {
+			page1 := MultiPaginatedResponse(1, 1, 2, uaa.User{ID: "test-user-1"})
+			page2 := MultiPaginatedResponse(2, 1, 2, uaa.User{ID: "test-user-2"})
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.URL.Path).To(Equal(uaa.UsersEndpoint))
+				Expect(req.URL.Query().Get("filter")).To(Equal("id pr"))
+				w.WriteHeader(http.StatusOK)
+				if called == 1 {
+					Expect(req.URL.Query().Get("startIndex")).To(Equal("1"))
+					Expect(req.URL.Query().Get("count")).To(Equal("100"))
+					w.Write([]byte(page1))
+				} else {
+					Expect(req.URL.Query().Get("startIndex")).To(Equal("2"))
+					Expect(req.URL.Query().Get("count")).To(Equal("1"))
+					w.Write([]byte(page2))
+				}
+			})
+		})
+
+		it("fetches pages lazily as the iterator is consumed", func() {
+			var ids []string
+			for user, err := range a.Users(context.Background(), uaa.ListQuery{Filter: "id pr"}) {
+				Expect(err).NotTo(HaveOccurred())
+				ids = append(ids, user.ID)
+			}
+			Expect(ids).To(Equal([]string{"test-user-1", "test-user-2"}))
+			Expect(called).To(Equal(2))
+		})
+
+		it("stops fetching once the consumer stops iterating", func() {
+			for user, err := range a.Users(context.Background(), uaa.ListQuery{Filter: "id pr"}) {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.ID).To(Equal("test-user-1"))
+				break
+			}
+			Expect(called).To(Equal(1))
+		})
+
+		it("yields an error when a page cannot be fetched", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			var errs []error
+			for _, err := range a.Users(context.Background(), uaa.ListQuery{}) {
+				errs = append(errs, err)
+			}
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("does not make a request when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			for _, err := range a.Users(ctx, uaa.ListQuery{}) {
+				Expect(err).To(HaveOccurred())
+			}
+			Expect(called).To(Equal(0))
+		})
 	})
 
 	when("ListUsers()", func() {
@@ -25,6 +25,8 @@ var typesToProcess = []interface{}{
 	uaa.User{},
 	uaa.IdentityZone{},
 	uaa.MFAProvider{},
+	uaa.IdentityProvider{},
+	uaa.SAMLServiceProvider{},
 }
 
 func main() {
@@ -49,10 +51,21 @@ func main() {
 			t.SupportsAttributes = false
 		}
 
-		if typeName == "IdentityZone" || typeName == "MFAProvider" {
+		if typeName == "IdentityZone" || typeName == "MFAProvider" || typeName == "IdentityProvider" || typeName == "SAMLServiceProvider" {
 			t.SupportsPaging = false
 		}
 
+		if typeName == "MFAProvider" {
+			t.ValidatesOnCreate = true
+		}
+
+		if _, ok := rtype.FieldByName("Extra"); ok {
+			t.SupportsExtra = true
+			// IdentityProvider decodes its config itself, so its JSON
+			// methods are written by hand.
+			t.GeneratesJSONCodec = typeName != "IdentityProvider"
+		}
+
 		for i := 0; i < rtype.NumField(); i++ {
 			field := rtype.Field(i)
 
@@ -115,6 +128,9 @@ type typeGenerator struct {
 	IDFieldName         string        // the field name for the ID
 	SupportsAttributes  bool          // attributes can be supplied when listing
 	SupportsPaging      bool          // paging is supported
+	ValidatesOnCreate   bool          // Validate is called before creating
+	SupportsExtra       bool          // unmodelled JSON members are kept in Extra
+	GeneratesJSONCodec  bool          // MarshalJSON and UnmarshalJSON are generated to handle Extra
 	Fields              []structField // fields on the struct we're generating for (converted to columns)
 }
 
@@ -0,0 +1,214 @@
+package uaa
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupEdge is a membership edge in a GroupGraph: Member is a direct member of
+// the group with ID GroupID.
+type GroupEdge struct {
+	GroupID string
+	Member  GroupMember
+}
+
+// GroupGraph is the nested group membership reachable from a user or group.
+type GroupGraph struct {
+	// Root is the ID of the user or group the graph was built from.
+	Root string
+	// Groups holds every group in the graph other than Root, by ID.
+	Groups map[string]Group
+	// Edges holds the membership edges that were walked to build the graph.
+	Edges []GroupEdge
+}
+
+// GroupNames returns the sorted display names of the groups in the graph.
+func (g *GroupGraph) GroupNames() []string {
+	names := make([]string, 0, len(g.Groups))
+	for _, group := range g.Groups {
+		names = append(names, group.DisplayName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UserIDs returns the sorted IDs of the users in the graph.
+func (g *GroupGraph) UserIDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, edge := range g.Edges {
+		if edge.Member.Type == "USER" && !seen[edge.Member.Value] {
+			seen[edge.Member.Value] = true
+			ids = append(ids, edge.Member.Value)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// groupIndex is every group in the zone, indexed by ID and by the IDs of their
+// direct members.
+type groupIndex struct {
+	byID      map[string]Group
+	parentsOf map[string][]Group
+}
+
+func (a *API) buildGroupIndex() (*groupIndex, error) {
+	groups, err := a.ListAllGroups("", "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	index := &groupIndex{
+		byID:      make(map[string]Group, len(groups)),
+		parentsOf: map[string][]Group{},
+	}
+	for _, group := range groups {
+		index.byID[group.ID] = group
+		for _, member := range group.Members {
+			index.parentsOf[member.Value] = append(index.parentsOf[member.Value], group)
+		}
+	}
+	return index, nil
+}
+
+// EffectiveGroups returns the graph of groups that the user with the given ID
+// belongs to, either directly or through nested group membership. Membership
+// cycles are detected, and each group is visited once.
+func (a *API) EffectiveGroups(userID string) (*GroupGraph, error) {
+	if userID == "" {
+		return nil, errors.New("userID cannot be blank")
+	}
+	index, err := a.buildGroupIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &GroupGraph{Root: userID, Groups: map[string]Group{}}
+	visited := map[string]bool{userID: true}
+	queue := []string{userID}
+	for len(queue) > 0 {
+		memberID := queue[0]
+		queue = queue[1:]
+		for _, parent := range index.parentsOf[memberID] {
+			for _, member := range parent.Members {
+				if member.Value == memberID {
+					graph.Edges = append(graph.Edges, GroupEdge{GroupID: parent.ID, Member: member})
+				}
+			}
+			if visited[parent.ID] {
+				continue
+			}
+			visited[parent.ID] = true
+			graph.Groups[parent.ID] = parent
+			queue = append(queue, parent.ID)
+		}
+	}
+	return graph, nil
+}
+
+// GroupClosure returns the graph of users and groups that are members of the
+// group with the given ID, either directly or through nested group
+// membership. Membership cycles are detected, and each group is visited once.
+func (a *API) GroupClosure(groupID string) (*GroupGraph, error) {
+	if groupID == "" {
+		return nil, errors.New("groupID cannot be blank")
+	}
+	index, err := a.buildGroupIndex()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := index.byID[groupID]; !ok {
+		return nil, fmt.Errorf("group %v not found", groupID)
+	}
+
+	graph := &GroupGraph{Root: groupID, Groups: map[string]Group{}}
+	visited := map[string]bool{groupID: true}
+	queue := []string{groupID}
+	for len(queue) > 0 {
+		group := index.byID[queue[0]]
+		queue = queue[1:]
+		for _, member := range group.Members {
+			graph.Edges = append(graph.Edges, GroupEdge{GroupID: group.ID, Member: member})
+			if member.Type != "GROUP" || visited[member.Value] {
+				continue
+			}
+			visited[member.Value] = true
+			nested, ok := index.byID[member.Value]
+			if !ok {
+				continue
+			}
+			graph.Groups[nested.ID] = nested
+			queue = append(queue, nested.ID)
+		}
+	}
+	return graph, nil
+}
+
+// EffectiveScopes returns the sorted scopes that a token issued to the client
+// with the given ID on behalf of the user with the given ID would contain: the
+// intersection of the user's effective groups and the zone's default groups
+// with the client's scope. Wildcards in the client's scope are honored.
+//
+// zoneID is the ID of the user's zone, and may be empty when the API was
+// created for that zone with ForZone or WithZoneID. Reading the zone's
+// default groups requires the zones.read or zones.<zone id>.read scope.
+func (a *API) EffectiveScopes(userID string, clientID string, zoneID string) ([]string, error) {
+	client, err := a.GetClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := a.EffectiveGroups(userID)
+	if err != nil {
+		return nil, err
+	}
+	zone, err := a.identityZone(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := graph.GroupNames()
+	if zone.Config.UserConfig != nil {
+		for _, name := range zone.Config.UserConfig.DefaultGroups {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	var scopes []string
+	for _, name := range names {
+		for _, scope := range client.Scope {
+			if matchesScope(scope, name) {
+				scopes = append(scopes, name)
+				break
+			}
+		}
+	}
+	return scopes, nil
+}
+
+// matchesScope reports whether scope matches pattern, in which each * matches
+// any run of characters, as UAA matches wildcard scopes. No other character
+// in pattern is special.
+func matchesScope(pattern string, scope string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == scope
+	}
+	if !strings.HasPrefix(scope, parts[0]) {
+		return false
+	}
+	scope = scope[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(scope, part)
+		if i < 0 {
+			return false
+		}
+		scope = scope[i+len(part):]
+	}
+	return len(scope) >= len(last) && strings.HasSuffix(scope, last)
+}
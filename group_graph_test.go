@@ -0,0 +1,162 @@
+package uaa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testGroupGraph(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	// admins -> operators -> readers -> admins (a cycle), with users in each.
+	groups := []uaa.Group{
+		{ID: "admins-id", DisplayName: "cloud_controller.admin", Members: []uaa.GroupMember{
+			{Origin: "uaa", Type: "USER", Value: "alice-id"},
+			{Origin: "uaa", Type: "GROUP", Value: "operators-id"},
+		}},
+		{ID: "operators-id", DisplayName: "cloud_controller.write", Members: []uaa.GroupMember{
+			{Origin: "uaa", Type: "USER", Value: "bob-id"},
+			{Origin: "uaa", Type: "GROUP", Value: "readers-id"},
+		}},
+		{ID: "readers-id", DisplayName: "cloud_controller.read", Members: []uaa.GroupMember{
+			{Origin: "uaa", Type: "USER", Value: "carol-id"},
+			{Origin: "uaa", Type: "GROUP", Value: "admins-id"},
+		}},
+		{ID: "other-id", DisplayName: "uaa.admin", Members: []uaa.GroupMember{
+			{Origin: "uaa", Type: "USER", Value: "dave-id"},
+		}},
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case uaa.GroupsEndpoint:
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(groups[0], groups[1], groups[2], groups[3])))
+				Expect(err).NotTo(HaveOccurred())
+			case uaa.ClientsEndpoint + "/cf":
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{"client_id":"cf","scope":["cloud_controller.read","uaa.*"]}`))
+				Expect(err).NotTo(HaveOccurred())
+			case uaa.ClientsEndpoint + "/login":
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{"client_id":"login","scope":["openid","cloud_controller.rea?","cloud_controller.[a-z]*"]}`))
+				Expect(err).NotTo(HaveOccurred())
+			case uaa.IdentityZonesEndpoint + "/uaa":
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{"id":"uaa","subdomain":"","name":"uaa","config":{"userConfig":{"defaultGroups":["openid","password.write"]}}}`))
+				Expect(err).NotTo(HaveOccurred())
+			case uaa.IdentityZonesEndpoint + "/tenant":
+				Expect(req.Header.Get("X-Identity-Zone-Id")).To(Equal("tenant"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{"id":"tenant","subdomain":"tenant","name":"tenant","config":{"userConfig":{"defaultGroups":["uaa.offline_token"]}}}`))
+				Expect(err).NotTo(HaveOccurred())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("EffectiveGroups()", func() {
+		it("returns an error when the userID is empty", func() {
+			_, err := a.EffectiveGroups("")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("walks nested memberships upwards without looping on cycles", func() {
+			graph, err := a.EffectiveGroups("carol-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(graph.Root).To(Equal("carol-id"))
+			Expect(graph.GroupNames()).To(Equal([]string{"cloud_controller.admin", "cloud_controller.read", "cloud_controller.write"}))
+			Expect(graph.Edges).To(ContainElement(uaa.GroupEdge{GroupID: "readers-id", Member: uaa.GroupMember{Origin: "uaa", Type: "USER", Value: "carol-id"}}))
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns an empty graph for a user in no groups", func() {
+			graph, err := a.EffectiveGroups("nobody-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(graph.Groups).To(BeEmpty())
+		})
+	})
+
+	when("GroupClosure()", func() {
+		it("walks nested memberships downwards without looping on cycles", func() {
+			graph, err := a.GroupClosure("operators-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(graph.GroupNames()).To(Equal([]string{"cloud_controller.admin", "cloud_controller.read"}))
+			Expect(graph.UserIDs()).To(Equal([]string{"alice-id", "bob-id", "carol-id"}))
+		})
+
+		it("returns an error when the group does not exist", func() {
+			_, err := a.GroupClosure("missing-id")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	when("EffectiveScopes()", func() {
+		it("intersects the effective groups with the client's scope", func() {
+			scopes, err := a.EffectiveScopes("carol-id", "cf", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scopes).To(Equal([]string{"cloud_controller.read"}))
+		})
+
+		it("honors wildcards in the client's scope", func() {
+			scopes, err := a.EffectiveScopes("dave-id", "cf", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scopes).To(Equal([]string{"uaa.admin"}))
+		})
+
+		it("includes the zone's default groups", func() {
+			scopes, err := a.EffectiveScopes("nobody-id", "login", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scopes).To(Equal([]string{"openid"}))
+		})
+
+		it("treats only * in the client's scope as a wildcard", func() {
+			scopes, err := a.EffectiveScopes("carol-id", "login", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scopes).To(Equal([]string{"openid"}))
+		})
+
+		it("uses the zone of an API for a zone", func() {
+			scopes, err := a.ForZone("tenant").EffectiveScopes("dave-id", "cf", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scopes).To(Equal([]string{"uaa.admin", "uaa.offline_token"}))
+		})
+
+		it("requires a zone ID when the API is not for a zone", func() {
+			_, err := a.EffectiveScopes("carol-id", "cf", "")
+			Expect(err).To(MatchError("zoneID cannot be blank when the API is not for a zone"))
+		})
+
+		it("returns an error when the client cannot be found", func() {
+			_, err := a.EffectiveScopes("carol-id", "missing", "uaa")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,255 @@
+package uaa
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SyncGroupMembersOptions configures SyncGroupMembers.
+type SyncGroupMembersOptions struct {
+	// DryRun computes the plan without applying it.
+	DryRun bool
+	// ManagedOrigins restricts removals to current members from these
+	// origins, so that members managed elsewhere (for example, by LDAP group
+	// mapping) are left alone. If empty, members from any origin may be
+	// removed.
+	ManagedOrigins []string
+}
+
+// GroupMemberAction is a change made to a group's membership.
+type GroupMemberAction string
+
+// Valid GroupMemberAction values.
+const (
+	GroupMemberAdd    = GroupMemberAction("add")
+	GroupMemberRemove = GroupMemberAction("remove")
+)
+
+// GroupMemberChange is a single planned change to a group's membership, and
+// the error encountered applying it, if any.
+type GroupMemberChange struct {
+	Action GroupMemberAction
+	Member GroupMember
+	Err    error
+}
+
+// GroupMembersSyncResult is the plan computed by SyncGroupMembers and the
+// outcome of applying it.
+type GroupMembersSyncResult struct {
+	GroupID string
+	Changes []GroupMemberChange
+	Applied bool
+}
+
+// Failed returns the changes that could not be applied.
+func (r *GroupMembersSyncResult) Failed() []GroupMemberChange {
+	var failed []GroupMemberChange
+	for _, change := range r.Changes {
+		if change.Err != nil {
+			failed = append(failed, change)
+		}
+	}
+	return failed
+}
+
+// SyncGroupMembers reconciles the members of the group with the given ID with
+// the desired members: members that are not desired are removed, and desired
+// members that are missing are added. Members are matched on value, entity
+// type and origin; a blank entity type is treated as "USER" and a blank origin
+// as "uaa". Removals are applied before additions. Every change is attempted,
+// and the error for each change that fails is recorded in the result; if any
+// change fails, an error is also returned.
+func (a *API) SyncGroupMembers(groupID string, desired []GroupMember, opts SyncGroupMembersOptions) (*GroupMembersSyncResult, error) {
+	if groupID == "" {
+		return nil, errors.New("groupID cannot be blank")
+	}
+	current, err := a.ListGroupMembers(groupID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupMembersSyncResult{GroupID: groupID}
+	wanted := map[string]bool{}
+	for _, member := range desired {
+		wanted[groupMemberKey(member)] = true
+	}
+	existing := map[string]bool{}
+	for _, member := range current {
+		key := groupMemberKey(member)
+		existing[key] = true
+		if wanted[key] {
+			continue
+		}
+		if len(opts.ManagedOrigins) > 0 && !contains(opts.ManagedOrigins, normalizeGroupMember(member).Origin) {
+			continue
+		}
+		result.Changes = append(result.Changes, GroupMemberChange{Action: GroupMemberRemove, Member: normalizeGroupMember(member)})
+	}
+	for _, member := range desired {
+		key := groupMemberKey(member)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		result.Changes = append(result.Changes, GroupMemberChange{Action: GroupMemberAdd, Member: normalizeGroupMember(member)})
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	failures := 0
+	for i, change := range result.Changes {
+		m := change.Member
+		switch change.Action {
+		case GroupMemberRemove:
+			err = a.RemoveGroupMember(groupID, m.Value, m.Type, m.Origin)
+		case GroupMemberAdd:
+			err = a.AddGroupMember(groupID, m.Value, m.Type, m.Origin)
+		}
+		if err != nil {
+			result.Changes[i].Err = err
+			failures++
+		}
+	}
+	result.Applied = true
+	if failures > 0 {
+		return result, fmt.Errorf("%d of %d membership changes to group %v failed", failures, len(result.Changes), groupID)
+	}
+	return result, nil
+}
+
+func normalizeGroupMember(member GroupMember) GroupMember {
+	if member.Origin == "" {
+		member.Origin = "uaa"
+	}
+	if member.Type == "" {
+		member.Type = "USER"
+	}
+	member.Entity = nil
+	return member
+}
+
+func groupMemberKey(member GroupMember) string {
+	member = normalizeGroupMember(member)
+	return member.Type + "\x00" + member.Origin + "\x00" + member.Value
+}
+
+// SyncGroupMappingsOptions configures SyncGroupMappings.
+type SyncGroupMappingsOptions struct {
+	// DryRun computes the plan without applying it.
+	DryRun bool
+}
+
+// GroupMappingAction is a change made to an origin's external group mappings.
+type GroupMappingAction string
+
+// Valid GroupMappingAction values.
+const (
+	GroupMappingMap   = GroupMappingAction("map")
+	GroupMappingUnmap = GroupMappingAction("unmap")
+)
+
+// GroupMappingChange is a single planned change to an origin's external group
+// mappings, and the error encountered applying it, if any.
+type GroupMappingChange struct {
+	Action  GroupMappingAction
+	Mapping GroupMapping
+	Err     error
+}
+
+// GroupMappingsSyncResult is the plan computed by SyncGroupMappings and the
+// outcome of applying it.
+type GroupMappingsSyncResult struct {
+	Origin  string
+	Changes []GroupMappingChange
+	Applied bool
+}
+
+// Failed returns the changes that could not be applied.
+func (r *GroupMappingsSyncResult) Failed() []GroupMappingChange {
+	var failed []GroupMappingChange
+	for _, change := range r.Changes {
+		if change.Err != nil {
+			failed = append(failed, change)
+		}
+	}
+	return failed
+}
+
+// SyncGroupMappings reconciles the external group mappings for the given
+// origin with the desired mappings: mappings that are not desired are
+// removed, and desired mappings that are missing are created. A desired
+// mapping may identify its group by GroupID or, if GroupID is blank, by
+// DisplayName. If no origin is supplied, the origin will be "ldap". Removals
+// are applied before additions. Every change is attempted, and the error for
+// each change that fails is recorded in the result; if any change fails, an
+// error is also returned.
+func (a *API) SyncGroupMappings(origin string, desired []GroupMapping, opts SyncGroupMappingsOptions) (*GroupMappingsSyncResult, error) {
+	if origin == "" {
+		origin = "ldap"
+	}
+	current, err := a.ListAllGroupMappings(origin)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GroupMappingsSyncResult{Origin: origin}
+	wanted := map[string]bool{}
+	for _, mapping := range desired {
+		wanted[groupMappingKey(mapping)] = true
+	}
+	existing := map[string]bool{}
+	for _, mapping := range current {
+		byID := groupMappingKey(GroupMapping{GroupID: mapping.GroupID, ExternalGroup: mapping.ExternalGroup})
+		byName := groupMappingKey(GroupMapping{DisplayName: mapping.DisplayName, ExternalGroup: mapping.ExternalGroup})
+		existing[byID] = true
+		existing[byName] = true
+		if wanted[byID] || wanted[byName] {
+			continue
+		}
+		result.Changes = append(result.Changes, GroupMappingChange{Action: GroupMappingUnmap, Mapping: mapping})
+	}
+	for _, mapping := range desired {
+		key := groupMappingKey(mapping)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		mapping.Origin = origin
+		result.Changes = append(result.Changes, GroupMappingChange{Action: GroupMappingMap, Mapping: mapping})
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	failures := 0
+	for i, change := range result.Changes {
+		m := change.Mapping
+		switch {
+		case change.Action == GroupMappingUnmap:
+			err = a.UnmapGroup(m.GroupID, m.ExternalGroup, origin)
+		case m.GroupID != "":
+			err = a.MapGroup(m.GroupID, m.ExternalGroup, origin)
+		default:
+			_, err = a.MapGroupByName(m.DisplayName, m.ExternalGroup, origin)
+		}
+		if err != nil {
+			result.Changes[i].Err = err
+			failures++
+		}
+	}
+	result.Applied = true
+	if failures > 0 {
+		return result, fmt.Errorf("%d of %d external group mapping changes for origin %v failed", failures, len(result.Changes), origin)
+	}
+	return result, nil
+}
+
+func groupMappingKey(mapping GroupMapping) string {
+	if mapping.GroupID != "" {
+		return "id\x00" + mapping.GroupID + "\x00" + mapping.ExternalGroup
+	}
+	return "name\x00" + mapping.DisplayName + "\x00" + mapping.ExternalGroup
+}
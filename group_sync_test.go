@@ -0,0 +1,194 @@
+package uaa_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testGroupSync(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s        *httptest.Server
+		handler  http.Handler
+		requests []string
+		a        *uaa.API
+	)
+
+	membersPath := uaa.GroupsEndpoint + "/group-id-1/members"
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		requests = nil
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests = append(requests, req.Method+" "+req.URL.Path)
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodGet && req.URL.Path == membersPath {
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`[
+					{"origin":"uaa","type":"USER","value":"keep-id"},
+					{"origin":"uaa","type":"USER","value":"remove-id"},
+					{"origin":"ldap","type":"USER","value":"ldap-id"}
+				]`))
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			if req.URL.Path == membersPath+"/bad-id" || req.URL.Path == membersPath && req.Method == http.MethodPost && bodyContains(req, "bad-id") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	desired := []uaa.GroupMember{
+		{Value: "keep-id"},
+		{Value: "add-id"},
+		{Value: "group-id-2", Type: "GROUP"},
+	}
+
+	when("SyncGroupMembers()", func() {
+		it("returns an error when the groupID is empty", func() {
+			_, err := a.SyncGroupMembers("", desired, uaa.SyncGroupMembersOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(requests).To(BeEmpty())
+		})
+
+		it("plans the changes without applying them on a dry run", func() {
+			result, err := a.SyncGroupMembers("group-id-1", desired, uaa.SyncGroupMembersOptions{DryRun: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Applied).To(BeFalse())
+			Expect(result.Changes).To(Equal([]uaa.GroupMemberChange{
+				{Action: uaa.GroupMemberRemove, Member: uaa.GroupMember{Origin: "uaa", Type: "USER", Value: "remove-id"}},
+				{Action: uaa.GroupMemberRemove, Member: uaa.GroupMember{Origin: "ldap", Type: "USER", Value: "ldap-id"}},
+				{Action: uaa.GroupMemberAdd, Member: uaa.GroupMember{Origin: "uaa", Type: "USER", Value: "add-id"}},
+				{Action: uaa.GroupMemberAdd, Member: uaa.GroupMember{Origin: "uaa", Type: "GROUP", Value: "group-id-2"}},
+			}))
+			Expect(requests).To(Equal([]string{"GET " + membersPath}))
+		})
+
+		it("only removes members from the managed origins", func() {
+			result, err := a.SyncGroupMembers("group-id-1", desired, uaa.SyncGroupMembersOptions{DryRun: true, ManagedOrigins: []string{"uaa"}})
+			Expect(err).NotTo(HaveOccurred())
+			for _, change := range result.Changes {
+				Expect(change.Member.Value).NotTo(Equal("ldap-id"))
+			}
+		})
+
+		it("applies removals before additions", func() {
+			result, err := a.SyncGroupMembers("group-id-1", desired, uaa.SyncGroupMembersOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Applied).To(BeTrue())
+			Expect(result.Failed()).To(BeEmpty())
+			Expect(requests).To(Equal([]string{
+				"GET " + membersPath,
+				"DELETE " + membersPath + "/remove-id",
+				"DELETE " + membersPath + "/ldap-id",
+				"POST " + membersPath,
+				"POST " + membersPath,
+			}))
+		})
+
+		it("reports the error for each failed change and attempts the rest", func() {
+			result, err := a.SyncGroupMembers("group-id-1", []uaa.GroupMember{{Value: "bad-id"}, {Value: "add-id"}}, uaa.SyncGroupMembersOptions{ManagedOrigins: []string{"ldap"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("1 of 3 membership changes to group group-id-1 failed"))
+			failed := result.Failed()
+			Expect(failed).To(HaveLen(1))
+			Expect(failed[0].Action).To(Equal(uaa.GroupMemberAdd))
+			Expect(failed[0].Member.Value).To(Equal("bad-id"))
+			Expect(requests).To(HaveLen(4))
+		})
+	})
+}
+
+func bodyContains(req *http.Request, s string) bool {
+	body, _ := ioutil.ReadAll(req.Body)
+	return strings.Contains(string(body), s)
+}
+
+func testGroupMappingSync(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s        *httptest.Server
+		requests []string
+		a        *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		requests = nil
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests = append(requests, req.Method+" "+req.URL.EscapedPath())
+			if req.Method == http.MethodGet {
+				Expect(req.URL.Query().Get("origin")).To(Equal("ldap"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(
+					uaa.GroupMapping{GroupID: "admins-id", DisplayName: "uaa.admin", ExternalGroup: "cn=admins,dc=example", Origin: "ldap"},
+					uaa.GroupMapping{GroupID: "readers-id", DisplayName: "cloud_controller.read", ExternalGroup: "cn=old,dc=example", Origin: "ldap"},
+				)))
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	desired := []uaa.GroupMapping{
+		{DisplayName: "uaa.admin", ExternalGroup: "cn=admins,dc=example"},
+		{GroupID: "readers-id", ExternalGroup: "cn=readers,dc=example"},
+		{DisplayName: "cloud_controller.write", ExternalGroup: "cn=writers,dc=example"},
+	}
+
+	when("SyncGroupMappings()", func() {
+		it("plans the changes without applying them on a dry run", func() {
+			result, err := a.SyncGroupMappings("", desired, uaa.SyncGroupMappingsOptions{DryRun: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Origin).To(Equal("ldap"))
+			Expect(result.Applied).To(BeFalse())
+			Expect(result.Changes).To(HaveLen(3))
+			Expect(result.Changes[0].Action).To(Equal(uaa.GroupMappingUnmap))
+			Expect(result.Changes[0].Mapping.ExternalGroup).To(Equal("cn=old,dc=example"))
+			Expect(result.Changes[1].Action).To(Equal(uaa.GroupMappingMap))
+			Expect(result.Changes[1].Mapping.GroupID).To(Equal("readers-id"))
+			Expect(result.Changes[2].Action).To(Equal(uaa.GroupMappingMap))
+			Expect(result.Changes[2].Mapping.DisplayName).To(Equal("cloud_controller.write"))
+			Expect(requests).To(HaveLen(1))
+		})
+
+		it("applies the changes", func() {
+			result, err := a.SyncGroupMappings("ldap", desired, uaa.SyncGroupMappingsOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Applied).To(BeTrue())
+			Expect(requests).To(Equal([]string{
+				"GET " + uaa.GroupsEndpoint + "/External",
+				"DELETE " + uaa.GroupsEndpoint + "/External/groupId/readers-id/externalGroup/cn=old%2Cdc=example/origin/ldap",
+				"POST " + uaa.GroupsEndpoint + "/External",
+				"POST " + uaa.GroupsEndpoint + "/External",
+			}))
+		})
+	})
+}
@@ -2,12 +2,16 @@ package uaa
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
+
+	"github.com/cloudfoundry-community/go-uaa/scim"
 )
 
 // GroupsEndpoint is the path to the groups resource.
@@ -25,6 +29,52 @@ type GroupMember struct {
 	Origin string `json:"origin,omitempty"`
 	Type   string `json:"type,omitempty"`
 	Value  string `json:"value,omitempty"`
+	// Entity is the expanded user or group, and is only populated when
+	// members are listed with returnEntities.
+	Entity json.RawMessage `json:"entity,omitempty"`
+	// Extra holds the members UAA returned that GroupMember does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the GroupMember, including its Extra members.
+func (m GroupMember) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(m)
+}
+
+// UnmarshalJSON decodes the GroupMember, keeping unmodelled members in Extra.
+func (m *GroupMember) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, m)
+}
+
+// User decodes the expanded entity of a member whose Type is "USER".
+func (m GroupMember) User() (*User, error) {
+	if m.Type != "USER" {
+		return nil, fmt.Errorf("member %v is a %v, not a USER", m.Value, m.Type)
+	}
+	user := &User{}
+	if err := m.decodeEntity(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Group decodes the expanded entity of a member whose Type is "GROUP".
+func (m GroupMember) Group() (*Group, error) {
+	if m.Type != "GROUP" {
+		return nil, fmt.Errorf("member %v is a %v, not a GROUP", m.Value, m.Type)
+	}
+	group := &Group{}
+	if err := m.decodeEntity(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (m GroupMember) decodeEntity(entity interface{}) error {
+	if len(m.Entity) == 0 {
+		return fmt.Errorf("member %v has no entity; list members with returnEntities to expand them", m.Value)
+	}
+	return json.Unmarshal(m.Entity, entity)
 }
 
 // Group is a container for users and groups.
@@ -36,6 +86,8 @@ type Group struct {
 	Description string        `json:"description,omitempty"`
 	Members     []GroupMember `json:"members,omitempty"`
 	Schemas     []string      `json:"schemas,omitempty"`
+	// Extra holds the members UAA returned that Group does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // paginatedGroupMappingList is the response from the API for a single page of group mappings.
@@ -108,6 +160,49 @@ func (a *API) RemoveGroupMember(groupID string, memberID string, entityType stri
 	return nil
 }
 
+// ListGroupMembers lists the members of the group with the given ID. If
+// returnEntities is true, each member's Entity holds the expanded user or
+// group, which can be decoded with GroupMember.User or GroupMember.Group
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#list-members.
+func (a *API) ListGroupMembers(groupID string, returnEntities bool) ([]GroupMember, error) {
+	if groupID == "" {
+		return nil, errors.New("groupID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/members", GroupsEndpoint, groupID))
+	query := url.Values{}
+	query.Set("returnEntities", strconv.FormatBool(returnEntities))
+	u.RawQuery = query.Encode()
+
+	var members []GroupMember
+	err := a.doJSON(http.MethodGet, &u, nil, &members, true)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// IsGroupMember returns true if the entity with the given memberID is a direct
+// member of the group with the given ID
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#retrieve-a-member.
+func (a *API) IsGroupMember(groupID string, memberID string) (bool, error) {
+	if groupID == "" {
+		return false, errors.New("groupID cannot be blank")
+	}
+	if memberID == "" {
+		return false, errors.New("memberID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/members/%s", GroupsEndpoint, groupID, memberID))
+	member := &GroupMember{}
+	err := a.doJSON(http.MethodGet, &u, nil, member, true)
+	if isStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // GetGroupByName gets the group with the given name
 // http://docs.cloudfoundry.org/api/uaa/version/4.14.0/index.html#list-4.
 func (a *API) GetGroupByName(name string, attributes string) (*Group, error) {
@@ -115,8 +210,8 @@ func (a *API) GetGroupByName(name string, attributes string) (*Group, error) {
 		return nil, errors.New("group name may not be blank")
 	}
 
-	filter := fmt.Sprintf(`displayName eq "%v"`, name)
-	groups, err := a.ListAllGroups(filter, "", attributes, "")
+	filter := scim.Eq("displayName", name)
+	groups, err := a.ListAllGroups(filter.String(), "", attributes, "")
 	if err != nil {
 		return nil, err
 	}
@@ -126,38 +221,118 @@ func (a *API) GetGroupByName(name string, attributes string) (*Group, error) {
 	return &groups[0], nil
 }
 
+// MapGroup maps the external group from the given origin to the group with
+// the given ID. If no origin is supplied, the origin will be "ldap". Use
+// CreateGroupMapping to get the created mapping
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#create-4.
 func (a *API) MapGroup(groupID string, externalGroup string, origin string) error {
-	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/External", GroupsEndpoint))
-	if origin == "" {
-		origin = "ldap"
+	_, err := a.CreateGroupMapping(groupID, externalGroup, origin)
+	return err
+}
+
+// CreateGroupMapping maps the external group from the given origin to the
+// group with the given ID, and returns the created mapping. If no origin is
+// supplied, the origin will be "ldap".
+func (a *API) CreateGroupMapping(groupID string, externalGroup string, origin string) (*GroupMapping, error) {
+	if groupID == "" {
+		return nil, errors.New("groupID cannot be blank")
 	}
-	mapped := &GroupMapping{}
-	mapping := GroupMapping{Origin: origin, GroupID: groupID, ExternalGroup: externalGroup}
+	return a.mapGroup(GroupMapping{GroupID: groupID, ExternalGroup: externalGroup, Origin: origin})
+}
+
+// MapGroupByName maps the external group from the given origin to the group
+// with the given display name, and returns the created mapping. If no origin
+// is supplied, the origin will be "ldap".
+func (a *API) MapGroupByName(displayName string, externalGroup string, origin string) (*GroupMapping, error) {
+	if displayName == "" {
+		return nil, errors.New("group name may not be blank")
+	}
+	return a.mapGroup(GroupMapping{DisplayName: displayName, ExternalGroup: externalGroup, Origin: origin})
+}
+
+func (a *API) mapGroup(mapping GroupMapping) (*GroupMapping, error) {
+	if mapping.ExternalGroup == "" {
+		return nil, errors.New("externalGroup cannot be blank")
+	}
+	if mapping.Origin == "" {
+		mapping.Origin = "ldap"
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/External", GroupsEndpoint))
 	j, err := json.Marshal(mapping)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	mapped := &GroupMapping{}
 	err = a.doJSON(http.MethodPost, &u, bytes.NewBuffer([]byte(j)), mapped, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return mapped, nil
 }
 
+// UnmapGroup removes the mapping of the external group from the given origin
+// to the group with the given ID. If no origin is supplied, the origin will be
+// "ldap"
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#remove-by-group-id.
 func (a *API) UnmapGroup(groupID string, externalGroup string, origin string) error {
+	if groupID == "" {
+		return errors.New("groupID cannot be blank")
+	}
+	return a.unmapGroup("groupId", groupID, externalGroup, origin)
+}
+
+// UnmapGroupByName removes the mapping of the external group from the given
+// origin to the group with the given display name. If no origin is supplied,
+// the origin will be "ldap"
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#remove-by-group-display-name.
+func (a *API) UnmapGroupByName(displayName string, externalGroup string, origin string) error {
+	if displayName == "" {
+		return errors.New("group name may not be blank")
+	}
+	return a.unmapGroup("displayName", displayName, externalGroup, origin)
+}
+
+// unmapGroup removes the mapping of the external group from the given origin
+// to the group identified by the given kind ("groupId" or "displayName") of
+// identifier.
+func (a *API) unmapGroup(kind string, group string, externalGroup string, origin string) error {
+	if externalGroup == "" {
+		return errors.New("externalGroup cannot be blank")
+	}
 	if origin == "" {
 		origin = "ldap"
 	}
-	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/External/groupId/%s/externalGroup/%s/origin/%s", GroupsEndpoint, groupID, externalGroup, origin))
+	u := urlWithEscapedSegments(*a.TargetURL, fmt.Sprintf("%s/External", GroupsEndpoint),
+		kind, group, "externalGroup", externalGroup, "origin", origin)
 	mapped := &GroupMapping{}
-	err := a.doJSON(http.MethodDelete, &u, nil, mapped, true)
-	if err != nil {
-		return err
+	return a.doJSON(http.MethodDelete, &u, nil, mapped, true)
+}
+
+// GetGroupMapping gets the mapping of the external group from the given origin
+// to the group with the given ID. If no origin is supplied, the origin will be
+// "ldap".
+func (a *API) GetGroupMapping(groupID string, externalGroup string, origin string) (*GroupMapping, error) {
+	if origin == "" {
+		origin = "ldap"
 	}
-	return nil
+	for mapping, err := range a.GroupMappings(context.Background(), origin) {
+		if err != nil {
+			return nil, err
+		}
+		if mapping.GroupID == groupID && mapping.ExternalGroup == externalGroup {
+			return &mapping, nil
+		}
+	}
+	return nil, fmt.Errorf("mapping of external group %v in origin %v to group %v not found", externalGroup, origin, groupID)
 }
 
+// ListGroupMappings lists a page of external group mappings for the given
+// origin, or for all origins if no origin is supplied.
 func (a *API) ListGroupMappings(origin string, startIndex int, itemsPerPage int) ([]GroupMapping, Page, error) {
+	return a.listGroupMappings(context.Background(), origin, startIndex, itemsPerPage)
+}
+
+func (a *API) listGroupMappings(ctx context.Context, origin string, startIndex int, itemsPerPage int) ([]GroupMapping, Page, error) {
 	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/External", GroupsEndpoint))
 	query := url.Values{}
 	if origin != "" {
@@ -174,7 +349,7 @@ func (a *API) ListGroupMappings(origin string, startIndex int, itemsPerPage int)
 	u.RawQuery = query.Encode()
 
 	mappings := &paginatedGroupMappingList{}
-	err := a.doJSON(http.MethodGet, &u, nil, mappings, true)
+	err := a.doJSONWithContext(ctx, http.MethodGet, &u, nil, mappings, true)
 	if err != nil {
 		return nil, Page{}, err
 	}
@@ -186,8 +361,16 @@ func (a *API) ListGroupMappings(origin string, startIndex int, itemsPerPage int)
 	return mappings.Resources, page, err
 }
 
-// ListAllGroups retrieves UAA groups
+// ListAllGroupMappings retrieves UAA group mappings. If the API was
+// constructed with WithPageConcurrency, pages after the first are fetched in
+// parallel.
 func (a *API) ListAllGroupMappings(origin string) ([]GroupMapping, error) {
+	if a.pageConcurrency > 1 {
+		return listAllConcurrently(a.pageConcurrency, func(startIndex int, itemsPerPage int) ([]GroupMapping, Page, error) {
+			return a.ListGroupMappings(origin, startIndex, itemsPerPage)
+		})
+	}
+
 	page := Page{
 		StartIndex:   1,
 		ItemsPerPage: 100,
@@ -212,3 +395,34 @@ func (a *API) ListAllGroupMappings(origin string) ([]GroupMapping, error) {
 	}
 	return results, nil
 }
+
+// GroupMappings returns an iterator over the external group mappings for the
+// given origin. Pages are fetched lazily as the iterator is consumed, and no
+// further pages are fetched once the consumer stops iterating.
+func (a *API) GroupMappings(ctx context.Context, origin string) iter.Seq2[GroupMapping, error] {
+	return func(yield func(GroupMapping, error) bool) {
+		page := Page{
+			StartIndex:   1,
+			ItemsPerPage: 100,
+		}
+
+		for {
+			currentPage, p, err := a.listGroupMappings(ctx, origin, page.StartIndex, page.ItemsPerPage)
+			if err != nil {
+				yield(GroupMapping{}, err)
+				return
+			}
+			for _, mapping := range currentPage {
+				if !yield(mapping, nil) {
+					return
+				}
+			}
+
+			if len(currentPage) == 0 || (p.StartIndex+p.ItemsPerPage) > p.TotalResults {
+				return
+			}
+			page = p
+			page.StartIndex = p.StartIndex + p.ItemsPerPage
+		}
+	}
+}
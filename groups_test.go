@@ -1,7 +1,10 @@
 package uaa_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -100,6 +103,18 @@ func testGroupsExtra(t *testing.T, when spec.G, it spec.S) {
 		}
 	})
 
+	when("group members have members go-uaa does not model", func() {
+		it("encodes them again", func() {
+			groupJSON := `{"id": "g1", "displayName": "uaa.user", "members": [{"origin": "uaa", "type": "USER", "value": "user-1", "futureMemberField": true}]}`
+			var group uaa.Group
+			Expect(json.Unmarshal([]byte(groupJSON), &group)).To(Succeed())
+			Expect(group.Members[0].Extra).To(HaveKey("futureMemberField"))
+			j, err := json.Marshal(group)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(groupJSON))
+		})
+	})
+
 	when("GetGroupByName()", func() {
 		when("when no group name is specified", func() {
 			it("returns an error", func() {
@@ -215,4 +230,253 @@ func testGroupsExtra(t *testing.T, when spec.G, it spec.S) {
 			Expect(called).To(Equal(2))
 		})
 	})
+
+	when("GroupMappings()", func() {
+		it("fetches pages lazily and stops when the consumer stops", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint + "/External"))
+				Expect(req.URL.Query().Get("origin")).To(Equal("ldap"))
+				var response string
+				switch req.URL.Query().Get("startIndex") {
+				case "1":
+					response = MultiPaginatedResponse(1, 1, 3, uaa.GroupMapping{GroupID: "group-id-1"})
+				case "2":
+					response = MultiPaginatedResponse(2, 1, 3, uaa.GroupMapping{GroupID: "group-id-2"})
+				default:
+					response = MultiPaginatedResponse(3, 1, 3, uaa.GroupMapping{GroupID: "group-id-3"})
+				}
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(response))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			var ids []string
+			for mapping, err := range a.GroupMappings(context.Background(), "ldap") {
+				Expect(err).NotTo(HaveOccurred())
+				ids = append(ids, mapping.GroupID)
+				if len(ids) == 2 {
+					break
+				}
+			}
+			Expect(ids).To(Equal([]string{"group-id-1", "group-id-2"}))
+			Expect(called).To(Equal(2))
+		})
+	})
+
+	when("ListGroupMembers()", func() {
+		it("returns an error when the groupID is empty", func() {
+			_, err := a.ListGroupMembers("", false)
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("lists the members of the group", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(fmt.Sprintf("%s/%s/members", uaa.GroupsEndpoint, "group-id-1")))
+				Expect(req.URL.Query().Get("returnEntities")).To(Equal("false"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`[{"origin":"uaa","type":"USER","value":"user-id-1"},{"origin":"uaa","type":"GROUP","value":"group-id-2"}]`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			members, err := a.ListGroupMembers("group-id-1", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(Equal([]uaa.GroupMember{
+				{Origin: "uaa", Type: "USER", Value: "user-id-1"},
+				{Origin: "uaa", Type: "GROUP", Value: "group-id-2"},
+			}))
+			_, err = members[0].User()
+			Expect(err).To(HaveOccurred())
+		})
+
+		it("expands the member entities when asked to", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get("returnEntities")).To(Equal("true"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(fmt.Sprintf(`[{"origin":"uaa","type":"USER","value":"00000000-0000-0000-0000-000000000001","entity":%s},{"origin":"uaa","type":"GROUP","value":"00000000-0000-0000-0000-000000000002","entity":%s}]`, MarcusUserResponse, CloudControllerReadGroupResponse)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			members, err := a.ListGroupMembers("group-id-1", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(2))
+
+			user, err := members[0].User()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.Username).To(Equal("marcus@stoicism.com"))
+			_, err = members[0].Group()
+			Expect(err).To(HaveOccurred())
+
+			group, err := members[1].Group()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(group.DisplayName).To(Equal("cloud_controller.read"))
+		})
+
+		it("returns an error when the request fails", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+			_, err := a.ListGroupMembers("group-id-1", false)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	when("IsGroupMember()", func() {
+		it("returns true when the member is found", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(fmt.Sprintf("%s/%s/members/%s", uaa.GroupsEndpoint, "group-id-1", "user-id-1")))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{"origin":"uaa","type":"USER","value":"user-id-1"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			isMember, err := a.IsGroupMember("group-id-1", "user-id-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isMember).To(BeTrue())
+		})
+
+		it("returns false when the member is not found", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				_, err := w.Write([]byte(`{"error":"scim_resource_not_found"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			isMember, err := a.IsGroupMember("group-id-1", "user-id-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isMember).To(BeFalse())
+		})
+
+		it("returns an error when the request fails", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			})
+			_, err := a.IsGroupMember("group-id-1", "user-id-1")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	when("MapGroup()", func() {
+		it("creates the mapping", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint + "/External"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"groupId":"group-id-1","externalGroup":"cn=admins,ou=groups,dc=example,dc=com","origin":"ldap"}`))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{"groupId":"group-id-1","displayName":"uaa.admin","externalGroup":"cn=admins,ou=groups,dc=example,dc=com","origin":"ldap"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			err := a.MapGroup("group-id-1", "cn=admins,ou=groups,dc=example,dc=com", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+	})
+
+	when("CreateGroupMapping()", func() {
+		it("creates the mapping and returns it", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint + "/External"))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{"groupId":"group-id-1","displayName":"uaa.admin","externalGroup":"cn=admins,ou=groups,dc=example,dc=com","origin":"ldap"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			mapping, err := a.CreateGroupMapping("group-id-1", "cn=admins,ou=groups,dc=example,dc=com", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapping.DisplayName).To(Equal("uaa.admin"))
+		})
+
+		it("returns an error when the groupID is empty", func() {
+			_, err := a.CreateGroupMapping("", "admins", "ldap")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("MapGroupByName()", func() {
+		it("creates the mapping using the group's display name", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint + "/External"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"displayName":"uaa.admin","externalGroup":"admins","origin":"oidc"}`))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{"groupId":"group-id-1","displayName":"uaa.admin","externalGroup":"admins","origin":"oidc"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			mapping, err := a.MapGroupByName("uaa.admin", "admins", "oidc")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapping.GroupID).To(Equal("group-id-1"))
+		})
+
+		it("returns an error when the display name is empty", func() {
+			_, err := a.MapGroupByName("", "admins", "oidc")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("UnmapGroup()", func() {
+		it("escapes the external group in the path", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.EscapedPath()).To(Equal(uaa.GroupsEndpoint + "/External/groupId/group-id-1/externalGroup/cn=a%2Fb%2Cou=groups/origin/ldap"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			err := a.UnmapGroup("group-id-1", "cn=a/b,ou=groups", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+	})
+
+	when("UnmapGroupByName()", func() {
+		it("removes the mapping using the group's display name", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.EscapedPath()).To(Equal(uaa.GroupsEndpoint + "/External/displayName/uaa.admin/externalGroup/cn=admins%2Cdc=example/origin/ldap"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			err := a.UnmapGroupByName("uaa.admin", "cn=admins,dc=example", "ldap")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns an error when the display name or external group is empty", func() {
+			Expect(a.UnmapGroupByName("", "admins", "ldap")).To(MatchError("group name may not be blank"))
+			Expect(a.UnmapGroupByName("uaa.admin", "", "ldap")).To(MatchError("externalGroup cannot be blank"))
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("GetGroupMapping()", func() {
+		it.Before(func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Path).To(Equal(uaa.GroupsEndpoint + "/External"))
+				Expect(req.URL.Query().Get("origin")).To(Equal("ldap"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(
+					uaa.GroupMapping{GroupID: "group-id-1", ExternalGroup: "cn=readers", Origin: "ldap"},
+					uaa.GroupMapping{GroupID: "group-id-1", ExternalGroup: "cn=admins", Origin: "ldap"},
+				)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		it("finds the mapping", func() {
+			mapping, err := a.GetGroupMapping("group-id-1", "cn=admins", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapping.ExternalGroup).To(Equal("cn=admins"))
+		})
+
+		it("returns an error when the mapping does not exist", func() {
+			_, err := a.GetGroupMapping("group-id-2", "cn=admins", "ldap")
+			Expect(err).To(HaveOccurred())
+		})
+	})
 }
@@ -0,0 +1,258 @@
+package uaa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+var identityproviderResponse string = `{
+	"id": "00000000-0000-0000-0000-000000000001",
+	"originKey": "my-oidc-provider",
+	"name": "My OIDC Provider",
+	"type": "oidc1.0",
+	"config": {
+		"emailDomain": null,
+		"additionalConfiguration": null,
+		"providerDescription": null,
+		"externalGroupsWhitelist": [ "uaa.user" ],
+		"attributeMappings": { "user_name": "email", "external_groups": [ "roles" ] },
+		"addShadowUserOnLogin": true,
+		"storeCustomAttributes": true,
+		"authUrl": "https://accounts.example.com/oauth2/authorize",
+		"tokenUrl": "https://accounts.example.com/oauth2/token",
+		"tokenKeyUrl": "https://accounts.example.com/oauth2/keys",
+		"showLinkText": true,
+		"relyingPartyId": "uaa",
+		"relyingPartySecret": null,
+		"scopes": [ "openid", "email" ],
+		"issuer": "https://accounts.example.com",
+		"responseType": "code",
+		"discoveryUrl": null,
+		"passwordGrantEnabled": false
+	},
+	"identityZoneId": "uaa",
+	"version": 0,
+	"active": true,
+	"created": 1529690500934,
+	"last_modified": 1529690500934
+}`
+
+var identityproviderListResponse string = `[{
+	"id": "00000000-0000-0000-0000-000000000001",
+	"originKey": "uaa",
+	"name": "uaa",
+	"type": "uaa",
+	"config": {
+		"passwordPolicy": {
+			"minLength": 0,
+			"maxLength": 255,
+			"requireUpperCaseCharacter": 0,
+			"requireLowerCaseCharacter": 0,
+			"requireDigit": 0,
+			"requireSpecialCharacter": 0,
+			"expirePasswordInMonths": 0
+		},
+		"lockoutPolicy": {
+			"lockoutPeriodSeconds": 300,
+			"lockoutAfterFailures": 5,
+			"countFailuresWithin": 3600
+		}
+	},
+	"identityZoneId": "uaa",
+	"active": true
+}, {
+	"id": "00000000-0000-0000-0000-000000000002",
+	"originKey": "ldap",
+	"name": "ldap",
+	"type": "ldap",
+	"config": "{\"baseUrl\":\"ldap://ldap.example.com:389\",\"ldapProfileFile\":\"ldap/ldap-search-and-bind.xml\"}",
+	"identityZoneId": "uaa",
+	"active": true
+}]`
+
+var testIdentityProviderValue uaa.IdentityProvider = uaa.IdentityProvider{
+	ID:        "00000000-0000-0000-0000-000000000001",
+	OriginKey: "my-oidc-provider",
+	Name:      "My OIDC Provider",
+	Config: &uaa.OIDCIdentityProviderConfig{
+		ExternalOAuthIdentityProviderDefinition: uaa.ExternalOAuthIdentityProviderDefinition{
+			ExternalIdentityProviderDefinition: uaa.ExternalIdentityProviderDefinition{
+				AttributeMappings:       map[string]interface{}{"user_name": "email"},
+				ExternalGroupsWhitelist: []string{"uaa.user"},
+			},
+			AuthURL:        "https://accounts.example.com/oauth2/authorize",
+			TokenURL:       "https://accounts.example.com/oauth2/token",
+			TokenKeyURL:    "https://accounts.example.com/oauth2/keys",
+			RelyingPartyID: "uaa",
+			Scopes:         []string{"openid", "email"},
+			Issuer:         "https://accounts.example.com",
+			ResponseType:   "code",
+		},
+	},
+	IdentityZoneID: "uaa",
+	Active:         true,
+	Created:        1529690500934,
+	LastModified:   1529690500934,
+}
+
+var testIdentityProviderJSON string = `{
+	"id": "00000000-0000-0000-0000-000000000001",
+	"originKey": "my-oidc-provider",
+	"name": "My OIDC Provider",
+	"type": "oidc1.0",
+	"config": {
+		"attributeMappings": { "user_name": "email" },
+		"externalGroupsWhitelist": [ "uaa.user" ],
+		"authUrl": "https://accounts.example.com/oauth2/authorize",
+		"tokenUrl": "https://accounts.example.com/oauth2/token",
+		"tokenKeyUrl": "https://accounts.example.com/oauth2/keys",
+		"relyingPartyId": "uaa",
+		"scopes": [ "openid", "email" ],
+		"issuer": "https://accounts.example.com",
+		"responseType": "code"
+	},
+	"identityZoneId": "uaa",
+	"active": true,
+	"created": 1529690500934,
+	"last_modified": 1529690500934
+}`
+
+func testIdentityProvidersExtra(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("decoding identity providers", func() {
+		it("decodes the config for the provider's type", func() {
+			var provider uaa.IdentityProvider
+			Expect(json.Unmarshal([]byte(identityproviderResponse), &provider)).To(Succeed())
+			config, ok := provider.Config.(*uaa.OIDCIdentityProviderConfig)
+			Expect(ok).To(BeTrue())
+			Expect(config.AuthURL).To(Equal("https://accounts.example.com/oauth2/authorize"))
+			Expect(config.ExternalGroupsWhitelist).To(Equal([]string{"uaa.user"}))
+			Expect(config.AttributeMappings).To(HaveKeyWithValue("user_name", "email"))
+			Expect(*config.AddShadowUserOnLogin).To(BeTrue())
+		})
+
+		it("decodes uaa password and lockout policies and string-encoded configs", func() {
+			var providers []uaa.IdentityProvider
+			Expect(json.Unmarshal([]byte(identityproviderListResponse), &providers)).To(Succeed())
+			Expect(providers).To(HaveLen(2))
+
+			uaaConfig, ok := providers[0].Config.(*uaa.UAAIdentityProviderConfig)
+			Expect(ok).To(BeTrue())
+			Expect(uaaConfig.PasswordPolicy.MaxLength).To(Equal(255))
+			Expect(uaaConfig.LockoutPolicy.LockoutAfterFailures).To(Equal(5))
+
+			ldapConfig, ok := providers[1].Config.(*uaa.LDAPIdentityProviderConfig)
+			Expect(ok).To(BeTrue())
+			Expect(ldapConfig.BaseURL).To(Equal("ldap://ldap.example.com:389"))
+			Expect(ldapConfig.LDAPProfileFile).To(Equal("ldap/ldap-search-and-bind.xml"))
+		})
+
+		it("encodes again the config members go-uaa does not model", func() {
+			for _, raw := range []string{
+				`{"originKey":"uaa","name":"uaa","type":"uaa","config":{"passwordPolicy":{"minLength":0,"maxLength":255,"requireUpperCaseCharacter":0,"requireLowerCaseCharacter":0,"requireDigit":0,"requireSpecialCharacter":0,"expirePasswordInMonths":0},"futureUaaField":true},"active":true}`,
+				`{"originKey":"ldap","name":"ldap","type":"ldap","config":{"baseUrl":"ldap://ldap.example.com:389","futureLdapField":"x"},"active":true}`,
+				`{"originKey":"saml","name":"saml","type":"saml","config":{"metaDataLocation":"https://idp.example.com/metadata","emailDomain":["example.com"],"futureSamlField":{"a":1}},"active":true}`,
+				`{"originKey":"oidc","name":"oidc","type":"oidc1.0","config":{"authUrl":"https://accounts.example.com/authorize","jwtClientAuthentication":{"kid":"key-1"},"userPropagationParameter":"login_hint"},"active":true}`,
+				`{"originKey":"oauth","name":"oauth","type":"oauth2.0","config":{"tokenUrl":"https://accounts.example.com/token","futureOAuthField":[1,2]},"active":true}`,
+			} {
+				var provider uaa.IdentityProvider
+				Expect(json.Unmarshal([]byte(raw), &provider)).To(Succeed())
+				j, err := json.Marshal(provider)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(j).To(MatchJSON(raw))
+			}
+		})
+
+		it("preserves the config of unknown provider types", func() {
+			raw := `{"originKey":"keystone","name":"keystone","type":"keystone","config":{"baseUrl":"https://keystone.example.com"},"active":false}`
+			var provider uaa.IdentityProvider
+			Expect(json.Unmarshal([]byte(raw), &provider)).To(Succeed())
+			Expect(provider.Config).To(BeAssignableToTypeOf(uaa.RawIdentityProviderConfig{}))
+			j, err := json.Marshal(provider)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(raw))
+		})
+	})
+
+	when("TestIdentityProvider()", func() {
+		ldap := uaa.IdentityProvider{
+			OriginKey: "ldap",
+			Name:      "ldap",
+			Config: &uaa.LDAPIdentityProviderConfig{
+				BaseURL:         "ldap://ldap.example.com:389",
+				LDAPProfileFile: "ldap/ldap-search-and-bind.xml",
+			},
+		}
+
+		it("POSTs the provider and credentials to /identity-providers/test", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityProvidersEndpoint + "/test"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{
+					"provider": {
+						"originKey": "ldap",
+						"name": "ldap",
+						"type": "ldap",
+						"config": {"baseUrl": "ldap://ldap.example.com:389", "ldapProfileFile": "ldap/ldap-search-and-bind.xml"},
+						"active": false
+					},
+					"credentials": {"username": "marissa", "password": "koala"}
+				}`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`"ok"`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			Expect(a.TestIdentityProvider(ldap, "marissa", "koala")).To(Succeed())
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns an error when the credentials are rejected", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusExpectationFailed)
+				_, err := w.Write([]byte(`"bad credentials"`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			err := a.TestIdentityProvider(ldap, "marissa", "wrong")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("bad credentials"))
+		})
+
+		it("only tests ldap providers", func() {
+			err := a.TestIdentityProvider(testIdentityProviderValue, "marissa", "koala")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+}
@@ -0,0 +1,408 @@
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// IdentityProvidersEndpoint is the path to the identity providers resource.
+const IdentityProvidersEndpoint string = "/identity-providers"
+
+// Identity provider types, which determine the type of an identity
+// provider's Config.
+const (
+	IdentityProviderTypeUAA    = "uaa"
+	IdentityProviderTypeLDAP   = "ldap"
+	IdentityProviderTypeSAML   = "saml"
+	IdentityProviderTypeOIDC   = "oidc1.0"
+	IdentityProviderTypeOAuth2 = "oauth2.0"
+)
+
+// IdentityProviderConfig is the type-specific configuration of an identity
+// provider. It is one of *UAAIdentityProviderConfig,
+// *LDAPIdentityProviderConfig, *SAMLIdentityProviderConfig,
+// *OIDCIdentityProviderConfig, *OAuth2IdentityProviderConfig or, for types
+// this package does not know about, RawIdentityProviderConfig. Each config
+// type keeps the members it does not model, including those of the
+// definitions it embeds, in its Extra field.
+type IdentityProviderConfig interface {
+	identityProviderType() string
+}
+
+// IdentityProvider is a UAA identity provider
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#identity-providers.
+type IdentityProvider struct {
+	ID             string                 `json:"id,omitempty"`
+	OriginKey      string                 `json:"originKey"`
+	Name           string                 `json:"name"`
+	Type           string                 `json:"type"`
+	Config         IdentityProviderConfig `json:"config,omitempty"`
+	IdentityZoneID string                 `json:"identityZoneId,omitempty"`
+	Version        int                    `json:"version,omitempty"`
+	Active         bool                   `json:"active"`
+	AliasID        string                 `json:"aliasId,omitempty"`
+	AliasZoneID    string                 `json:"aliasZid,omitempty"`
+	Created        int                    `json:"created,omitempty"`
+	LastModified   int                    `json:"last_modified,omitempty"`
+	// Extra holds the members UAA returned that IdentityProvider does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Identifier returns the field used to uniquely identify an IdentityProvider.
+func (p IdentityProvider) Identifier() string {
+	return p.ID
+}
+
+type identityProviderAlias IdentityProvider
+
+type identityProviderJSON struct {
+	identityProviderAlias
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// MarshalJSON encodes the identity provider, filling in Type from the type
+// of Config when it is not set.
+func (p IdentityProvider) MarshalJSON() ([]byte, error) {
+	j := identityProviderJSON{identityProviderAlias: identityProviderAlias(p)}
+	if p.Config != nil {
+		if j.Type == "" {
+			j.Type = p.Config.identityProviderType()
+		}
+		c, err := json.Marshal(p.Config)
+		if err != nil {
+			return nil, err
+		}
+		j.Config = c
+	}
+	return encodeWithExtra(j, p.Extra)
+}
+
+// UnmarshalJSON decodes the identity provider, decoding Config into the
+// config type matching the provider's Type and keeping unmodelled members in
+// Extra.
+func (p *IdentityProvider) UnmarshalJSON(data []byte) error {
+	var j identityProviderJSON
+	extra, err := decodeWithExtra(data, &j)
+	if err != nil {
+		return err
+	}
+	*p = IdentityProvider(j.identityProviderAlias)
+	p.Extra = extra
+	if len(j.Config) == 0 || string(j.Config) == "null" {
+		return nil
+	}
+
+	config := j.Config
+	// Older UAA versions return the config as a JSON-encoded string.
+	var s string
+	if json.Unmarshal(config, &s) == nil {
+		config = json.RawMessage(s)
+	}
+
+	var c IdentityProviderConfig
+	switch p.Type {
+	case IdentityProviderTypeUAA:
+		c = &UAAIdentityProviderConfig{}
+	case IdentityProviderTypeLDAP:
+		c = &LDAPIdentityProviderConfig{}
+	case IdentityProviderTypeSAML:
+		c = &SAMLIdentityProviderConfig{}
+	case IdentityProviderTypeOIDC:
+		c = &OIDCIdentityProviderConfig{}
+	case IdentityProviderTypeOAuth2:
+		c = &OAuth2IdentityProviderConfig{}
+	default:
+		p.Config = RawIdentityProviderConfig{Type: p.Type, Config: config}
+		return nil
+	}
+	if err := json.Unmarshal(config, c); err != nil {
+		return fmt.Errorf("decoding %v identity provider config: %v", p.Type, err)
+	}
+	p.Config = c
+	return nil
+}
+
+// RawIdentityProviderConfig is the undecoded configuration of an identity
+// provider whose type is not known to this package.
+type RawIdentityProviderConfig struct {
+	Type   string
+	Config json.RawMessage
+}
+
+func (c RawIdentityProviderConfig) identityProviderType() string { return c.Type }
+
+// MarshalJSON encodes the raw configuration unchanged.
+func (c RawIdentityProviderConfig) MarshalJSON() ([]byte, error) {
+	if len(c.Config) == 0 {
+		return []byte("null"), nil
+	}
+	return c.Config, nil
+}
+
+// IdentityProviderDefinition holds the configuration common to every type
+// of identity provider.
+type IdentityProviderDefinition struct {
+	EmailDomain             []string               `json:"emailDomain,omitempty"`
+	ProviderDescription     string                 `json:"providerDescription,omitempty"`
+	AdditionalConfiguration map[string]interface{} `json:"additionalConfiguration,omitempty"`
+}
+
+// ExternalIdentityProviderDefinition holds the configuration common to
+// identity providers that authenticate users outside of UAA.
+type ExternalIdentityProviderDefinition struct {
+	IdentityProviderDefinition
+	// AttributeMappings maps UAA user attributes, such as "email",
+	// "given_name" or "external_groups", to the provider's attribute names.
+	AttributeMappings       map[string]interface{} `json:"attributeMappings,omitempty"`
+	ExternalGroupsWhitelist []string               `json:"externalGroupsWhitelist,omitempty"`
+	AddShadowUserOnLogin    *bool                  `json:"addShadowUserOnLogin,omitempty"`
+	StoreCustomAttributes   *bool                  `json:"storeCustomAttributes,omitempty"`
+}
+
+// PasswordPolicy is the password policy of a uaa identity provider.
+type PasswordPolicy struct {
+	MinLength                 int   `json:"minLength"`
+	MaxLength                 int   `json:"maxLength"`
+	RequireUpperCaseCharacter int   `json:"requireUpperCaseCharacter"`
+	RequireLowerCaseCharacter int   `json:"requireLowerCaseCharacter"`
+	RequireDigit              int   `json:"requireDigit"`
+	RequireSpecialCharacter   int   `json:"requireSpecialCharacter"`
+	ExpirePasswordInMonths    int   `json:"expirePasswordInMonths"`
+	PasswordNewerThan         int64 `json:"passwordNewerThan,omitempty"`
+}
+
+// LockoutPolicy is the account lockout policy of a uaa identity provider.
+type LockoutPolicy struct {
+	LockoutPeriodSeconds int `json:"lockoutPeriodSeconds"`
+	LockoutAfterFailures int `json:"lockoutAfterFailures"`
+	CountFailuresWithin  int `json:"countFailuresWithin"`
+}
+
+// UAAIdentityProviderConfig is the configuration of the internal uaa
+// identity provider.
+type UAAIdentityProviderConfig struct {
+	IdentityProviderDefinition
+	PasswordPolicy                *PasswordPolicy `json:"passwordPolicy,omitempty"`
+	LockoutPolicy                 *LockoutPolicy  `json:"lockoutPolicy,omitempty"`
+	DisableInternalUserManagement bool            `json:"disableInternalUserManagement,omitempty"`
+	// Extra holds the members UAA returned that UAAIdentityProviderConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (*UAAIdentityProviderConfig) identityProviderType() string { return IdentityProviderTypeUAA }
+
+// MarshalJSON encodes the UAAIdentityProviderConfig, including its Extra members.
+func (c UAAIdentityProviderConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the UAAIdentityProviderConfig, keeping unmodelled members in
+// Extra.
+func (c *UAAIdentityProviderConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// LDAPIdentityProviderConfig is the configuration of an ldap identity
+// provider.
+type LDAPIdentityProviderConfig struct {
+	ExternalIdentityProviderDefinition
+	LDAPProfileFile             string `json:"ldapProfileFile,omitempty"`
+	LDAPGroupFile               string `json:"ldapGroupFile,omitempty"`
+	BaseURL                     string `json:"baseUrl,omitempty"`
+	BindUserDN                  string `json:"bindUserDn,omitempty"`
+	BindPassword                string `json:"bindPassword,omitempty"`
+	UserSearchBase              string `json:"userSearchBase,omitempty"`
+	UserSearchFilter            string `json:"userSearchFilter,omitempty"`
+	UserDNPattern               string `json:"userDNPattern,omitempty"`
+	UserDNPatternDelimiter      string `json:"userDNPatternDelimiter,omitempty"`
+	PasswordAttributeName       string `json:"passwordAttributeName,omitempty"`
+	PasswordEncoder             string `json:"passwordEncoder,omitempty"`
+	LocalPasswordCompare        *bool  `json:"localPasswordCompare,omitempty"`
+	MailAttributeName           string `json:"mailAttributeName,omitempty"`
+	MailSubstitute              string `json:"mailSubstitute,omitempty"`
+	MailSubstituteOverridesLDAP *bool  `json:"mailSubstituteOverridesLdap,omitempty"`
+	SkipSSLVerification         *bool  `json:"skipSSLVerification,omitempty"`
+	TLSConfiguration            string `json:"tlsConfiguration,omitempty"`
+	GroupSearchBase             string `json:"groupSearchBase,omitempty"`
+	GroupSearchFilter           string `json:"groupSearchFilter,omitempty"`
+	GroupsIgnorePartialResults  *bool  `json:"groupsIgnorePartialResults,omitempty"`
+	AutoAddGroups               *bool  `json:"autoAddGroups,omitempty"`
+	GroupSearchSubTree          *bool  `json:"groupSearchSubTree,omitempty"`
+	MaxGroupSearchDepth         int    `json:"maxGroupSearchDepth,omitempty"`
+	GroupRoleAttribute          string `json:"groupRoleAttribute,omitempty"`
+	// Extra holds the members UAA returned that LDAPIdentityProviderConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (*LDAPIdentityProviderConfig) identityProviderType() string { return IdentityProviderTypeLDAP }
+
+// MarshalJSON encodes the LDAPIdentityProviderConfig, including its Extra members.
+func (c LDAPIdentityProviderConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the LDAPIdentityProviderConfig, keeping unmodelled members in
+// Extra.
+func (c *LDAPIdentityProviderConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// SAMLIdentityProviderConfig is the configuration of a saml identity
+// provider.
+type SAMLIdentityProviderConfig struct {
+	ExternalIdentityProviderDefinition
+	MetaDataLocation       string   `json:"metaDataLocation,omitempty"`
+	IDPEntityAlias         string   `json:"idpEntityAlias,omitempty"`
+	ZoneID                 string   `json:"zoneId,omitempty"`
+	NameID                 string   `json:"nameID,omitempty"`
+	AssertionConsumerIndex int      `json:"assertionConsumerIndex,omitempty"`
+	MetadataTrustCheck     *bool    `json:"metadataTrustCheck,omitempty"`
+	ShowSAMLLink           *bool    `json:"showSamlLink,omitempty"`
+	LinkText               string   `json:"linkText,omitempty"`
+	IconURL                string   `json:"iconUrl,omitempty"`
+	GroupMappingMode       string   `json:"groupMappingMode,omitempty"`
+	SkipSSLValidation      *bool    `json:"skipSslValidation,omitempty"`
+	SocketFactoryClassName string   `json:"socketFactoryClassName,omitempty"`
+	AuthnContext           []string `json:"authnContext,omitempty"`
+	// Extra holds the members UAA returned that SAMLIdentityProviderConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (*SAMLIdentityProviderConfig) identityProviderType() string { return IdentityProviderTypeSAML }
+
+// MarshalJSON encodes the SAMLIdentityProviderConfig, including its Extra members.
+func (c SAMLIdentityProviderConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the SAMLIdentityProviderConfig, keeping unmodelled members in
+// Extra.
+func (c *SAMLIdentityProviderConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// ExternalOAuthIdentityProviderDefinition holds the configuration common to
+// oidc1.0 and oauth2.0 identity providers.
+type ExternalOAuthIdentityProviderDefinition struct {
+	ExternalIdentityProviderDefinition
+	AuthURL                   string            `json:"authUrl,omitempty"`
+	TokenURL                  string            `json:"tokenUrl,omitempty"`
+	TokenKeyURL               string            `json:"tokenKeyUrl,omitempty"`
+	TokenKey                  string            `json:"tokenKey,omitempty"`
+	UserInfoURL               string            `json:"userInfoUrl,omitempty"`
+	LogoutURL                 string            `json:"logoutUrl,omitempty"`
+	LinkText                  string            `json:"linkText,omitempty"`
+	ShowLinkText              *bool             `json:"showLinkText,omitempty"`
+	ClientAuthInBody          bool              `json:"clientAuthInBody,omitempty"`
+	SkipSSLValidation         bool              `json:"skipSslValidation,omitempty"`
+	RelyingPartyID            string            `json:"relyingPartyId,omitempty"`
+	RelyingPartySecret        string            `json:"relyingPartySecret,omitempty"`
+	Scopes                    []string          `json:"scopes,omitempty"`
+	Issuer                    string            `json:"issuer,omitempty"`
+	ResponseType              string            `json:"responseType,omitempty"`
+	PKCE                      *bool             `json:"pkce,omitempty"`
+	PerformRPInitiatedLogout  *bool             `json:"performRpInitiatedLogout,omitempty"`
+	CacheJwks                 *bool             `json:"cacheJwks,omitempty"`
+	AdditionalAuthzParameters map[string]string `json:"additionalAuthzParameters,omitempty"`
+}
+
+// OIDCIdentityProviderConfig is the configuration of an oidc1.0 identity
+// provider.
+type OIDCIdentityProviderConfig struct {
+	ExternalOAuthIdentityProviderDefinition
+	DiscoveryURL         string   `json:"discoveryUrl,omitempty"`
+	PasswordGrantEnabled bool     `json:"passwordGrantEnabled,omitempty"`
+	SetForwardHeader     bool     `json:"setForwardHeader,omitempty"`
+	Prompts              []Prompt `json:"prompts,omitempty"`
+	// Extra holds the members UAA returned that OIDCIdentityProviderConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (*OIDCIdentityProviderConfig) identityProviderType() string { return IdentityProviderTypeOIDC }
+
+// MarshalJSON encodes the OIDCIdentityProviderConfig, including its Extra members.
+func (c OIDCIdentityProviderConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the OIDCIdentityProviderConfig, keeping unmodelled members in
+// Extra.
+func (c *OIDCIdentityProviderConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// OAuth2IdentityProviderConfig is the configuration of an oauth2.0 identity
+// provider.
+type OAuth2IdentityProviderConfig struct {
+	ExternalOAuthIdentityProviderDefinition
+	CheckTokenURL string `json:"checkTokenUrl,omitempty"`
+	// Extra holds the members UAA returned that OAuth2IdentityProviderConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (*OAuth2IdentityProviderConfig) identityProviderType() string { return IdentityProviderTypeOAuth2 }
+
+// MarshalJSON encodes the OAuth2IdentityProviderConfig, including its Extra members.
+func (c OAuth2IdentityProviderConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the OAuth2IdentityProviderConfig, keeping unmodelled members in
+// Extra.
+func (c *OAuth2IdentityProviderConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// IdentityProviderTestCredentials are the user credentials used to test an
+// identity provider.
+type IdentityProviderTestCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type identityProviderTestRequest struct {
+	Provider    IdentityProvider                `json:"provider"`
+	Credentials IdentityProviderTestCredentials `json:"credentials"`
+}
+
+// TestIdentityProvider checks that the given LDAP identity provider
+// configuration can authenticate the user with the given credentials, without
+// saving the provider. It returns nil if authentication succeeds
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#test-connection.
+func (a *API) TestIdentityProvider(provider IdentityProvider, username string, password string) error {
+	if provider.Type == "" && provider.Config != nil {
+		provider.Type = provider.Config.identityProviderType()
+	}
+	if provider.Type != IdentityProviderTypeLDAP {
+		return fmt.Errorf("only %v identity providers can be tested", IdentityProviderTypeLDAP)
+	}
+	if username == "" {
+		return errors.New("username cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/test", IdentityProvidersEndpoint))
+	j, err := json.Marshal(identityProviderTestRequest{
+		Provider:    provider,
+		Credentials: IdentityProviderTestCredentials{Username: username, Password: password},
+	})
+	if err != nil {
+		return err
+	}
+	var result string
+	err = a.doJSON(http.MethodPost, &u, bytes.NewBuffer(j), &result, true)
+	if err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("identity provider test failed: %v", result)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package uaa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ZoneAdminAuthorities are the authorities CreateZoneAdminClient grants to a
+// zone administrator client.
+var ZoneAdminAuthorities = []string{
+	"clients.admin",
+	"scim.read",
+	"scim.write",
+	"idps.read",
+	"idps.write",
+	"uaa.resource",
+}
+
+// CreateZoneClient creates the given client in the zone with the given ID.
+// Unlike ForZone(zoneID).CreateClient, it is authorized by the caller's
+// zones.write authority in the current zone rather than by admin rights in
+// the target zone, so it can bootstrap a zone's first client
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#create-client-2.
+func (a *API) CreateZoneClient(zoneID string, client Client) (*Client, error) {
+	if zoneID == "" {
+		return nil, errors.New("zoneID cannot be blank")
+	}
+	if client.ClientID == "" {
+		return nil, errorMissingValue("client_id")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/clients", IdentityZonesEndpoint, zoneID))
+	j, err := json.Marshal(client)
+	if err != nil {
+		return nil, err
+	}
+	created := &Client{}
+	err = a.doJSON(http.MethodPost, &u, bytes.NewBuffer(j), created, true)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// DeleteZoneClient deletes the client with the given ID from the zone with
+// the given ID
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#delete-client-2.
+func (a *API) DeleteZoneClient(zoneID string, clientID string) (*Client, error) {
+	if zoneID == "" {
+		return nil, errors.New("zoneID cannot be blank")
+	}
+	if clientID == "" {
+		return nil, errors.New("clientID cannot be blank")
+	}
+	u := urlWithEscapedSegments(*a.TargetURL, fmt.Sprintf("%s/%s/clients", IdentityZonesEndpoint, zoneID), clientID)
+	deleted := &Client{}
+	err := a.doJSON(http.MethodDelete, &u, nil, deleted, true)
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// CreateZoneAdminClient creates a client_credentials client in the zone with
+// the given ID that has the ZoneAdminAuthorities, so that the zone can then
+// be administered with an API authenticated as that client against the
+// zone's subdomain.
+func (a *API) CreateZoneAdminClient(zoneID string, clientID string, clientSecret string) (*Client, error) {
+	if clientSecret == "" {
+		return nil, errorMissingValueForGrantType("client_secret", CLIENTCREDENTIALS)
+	}
+	return a.CreateZoneClient(zoneID, Client{
+		ClientID:             clientID,
+		ClientSecret:         clientSecret,
+		AuthorizedGrantTypes: []string{string(CLIENTCREDENTIALS)},
+		Scope:                []string{"uaa.none"},
+		Authorities:          append([]string(nil), ZoneAdminAuthorities...),
+		AllowedProviders:     []string{"uaa"},
+	})
+}
@@ -0,0 +1,146 @@
+package uaa_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testZoneClients(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication(), uaa.WithZoneID("parent"))
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("ForZone()", func() {
+		it("sends requests to the given zone using the parent's client", func() {
+			var zones []string
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				zones = append(zones, req.Header.Get("X-Identity-Zone-Id"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`[]`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			zoned := a.ForZone("tenant")
+			Expect(zoned.Client).To(BeIdenticalTo(a.Client))
+			_, err := zoned.ListMFAProviders()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = a.ListMFAProviders()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zones).To(Equal([]string{"tenant", "parent"}))
+		})
+	})
+
+	when("ForZoneSubdomain()", func() {
+		it("targets the zone's subdomain using the parent's client", func() {
+			parent, err := uaa.New("https://login.example.com/uaa", uaa.WithNoAuthentication())
+			Expect(err).NotTo(HaveOccurred())
+			zoned, err := parent.ForZoneSubdomain("tenant")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zoned.TargetURL.String()).To(Equal("https://tenant.login.example.com/uaa"))
+			Expect(zoned.Client).To(BeIdenticalTo(parent.Client))
+			Expect(parent.TargetURL.String()).To(Equal("https://login.example.com/uaa"))
+		})
+
+		it("returns an error when the API has no target", func() {
+			zoned, err := (&uaa.API{}).ForZoneSubdomain("tenant")
+			Expect(err).To(MatchError("the target is missing"))
+			Expect(zoned).To(BeNil())
+		})
+	})
+
+	when("CreateZoneClient()", func() {
+		it("POSTs the client to /identity-zones/{id}/clients", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityZonesEndpoint + "/tenant/clients"))
+				Expect(req.Header.Get("X-Identity-Zone-Id")).To(Equal("parent"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"client_id": "tenant-client", "authorized_grant_types": ["client_credentials"], "client_secret": "secret"}`))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(`{"client_id": "tenant-client", "authorized_grant_types": ["client_credentials"]}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			created, err := a.CreateZoneClient("tenant", uaa.Client{ClientID: "tenant-client", ClientSecret: "secret", AuthorizedGrantTypes: []string{"client_credentials"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.ClientID).To(Equal("tenant-client"))
+			Expect(called).To(Equal(1))
+		})
+
+		it("requires a zone ID", func() {
+			_, err := a.CreateZoneClient("", uaa.Client{ClientID: "tenant-client"})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("CreateZoneAdminClient()", func() {
+		it("creates a client_credentials client with the zone admin authorities", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Path).To(Equal(uaa.IdentityZonesEndpoint + "/tenant/clients"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{
+					"client_id": "tenant-admin",
+					"client_secret": "secret",
+					"authorized_grant_types": ["client_credentials"],
+					"scope": ["uaa.none"],
+					"authorities": ["clients.admin", "scim.read", "scim.write", "idps.read", "idps.write", "uaa.resource"],
+					"allowedproviders": ["uaa"]
+				}`))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write(body)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			created, err := a.CreateZoneAdminClient("tenant", "tenant-admin", "secret")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Authorities).To(ContainElement("clients.admin"))
+		})
+
+		it("requires a secret", func() {
+			_, err := a.CreateZoneAdminClient("tenant", "tenant-admin", "")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("DeleteZoneClient()", func() {
+		it("DELETEs /identity-zones/{id}/clients/{clientID}", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal(uaa.IdentityZonesEndpoint + "/tenant/clients/tenant-admin"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(`{"client_id": "tenant-admin"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			deleted, err := a.DeleteZoneClient("tenant", "tenant-admin")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted.ClientID).To(Equal("tenant-admin"))
+		})
+	})
+}
@@ -0,0 +1,428 @@
+package uaa
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// CloneIdentityZoneOptions controls what CloneIdentityZone copies into the
+// new zone, and supplies the values UAA does not return.
+type CloneIdentityZoneOptions struct {
+	// Name and Description of the new zone. Name defaults to the subdomain,
+	// and Description to the source zone's description.
+	Name        string
+	Description string
+	// Config, when set, is called with the copied config before the new
+	// zone is created, so that it can be adjusted for the new zone.
+	Config func(config *IdentityZoneConfig)
+
+	Clients           bool // copy the source zone's clients
+	Groups            bool // copy the source zone's groups, without members
+	IdentityProviders bool // copy the source zone's identity providers
+	MFAProviders      bool // copy the source zone's MFA providers
+
+	// ClientSecrets are the secrets for the copied clients, keyed by client
+	// ID. Clients without an entry are given a generated secret that
+	// satisfies the new zone's client secret policy.
+	ClientSecrets map[string]string
+	// IdentityProviderSecrets are the relying party secrets of copied OIDC
+	// and OAuth identity providers, or the bind passwords of copied LDAP
+	// identity providers, keyed by origin key. UAA does not return them, so
+	// every such provider that is copied must have an entry.
+	IdentityProviderSecrets map[string]string
+}
+
+// CloneIdentityZoneResult holds what CloneIdentityZone created.
+type CloneIdentityZoneResult struct {
+	Zone              *IdentityZone
+	Clients           []Client
+	Groups            []Group
+	IdentityProviders []IdentityProvider
+	MFAProviders      []MFAProvider
+	// ClientSecrets are the secrets of the copied clients, keyed by client
+	// ID, including those that were generated.
+	ClientSecrets map[string]string
+}
+
+// CloneIdentityZone creates a new zone with the given ID and subdomain and a
+// copy of the source zone's config, then copies the resources selected in
+// opts from the source zone into it.
+//
+// UAA does not return secrets or private keys, so SAML keys without a private
+// key are not copied, the active token signing key is not copied unless
+// opts.Config supplies its key, and client and identity provider secrets are
+// taken from opts or generated. Missing identity provider secrets and a client secret
+// policy that cannot be satisfied are reported before anything is created.
+// Otherwise cloning stops at the first error and does not undo the steps
+// already taken; the returned result holds what was created so far.
+func (a *API) CloneIdentityZone(sourceID string, newID string, subdomain string, opts CloneIdentityZoneOptions) (*CloneIdentityZoneResult, error) {
+	if sourceID == "" {
+		return nil, errors.New("sourceID cannot be blank")
+	}
+	if subdomain == "" {
+		return nil, errors.New("subdomain cannot be blank")
+	}
+
+	result := &CloneIdentityZoneResult{ClientSecrets: map[string]string{}}
+	source, err := a.GetIdentityZone(sourceID)
+	if err != nil {
+		return result, err
+	}
+
+	zone, mfaConfig, err := cloneZone(*source, newID, subdomain, opts)
+	if err != nil {
+		return result, err
+	}
+	if opts.Clients {
+		if err := checkClientSecretPolicy(zone.Config.ClientSecretPolicy); err != nil {
+			return result, err
+		}
+	}
+
+	src := a.ForZone(sourceID)
+	var providers []IdentityProvider
+	if opts.IdentityProviders {
+		if providers, err = src.ListIdentityProviders(); err != nil {
+			return result, err
+		}
+		if err := setIdentityProviderSecrets(providers, opts.IdentityProviderSecrets); err != nil {
+			return result, err
+		}
+	}
+
+	result.Zone, err = a.CreateIdentityZone(zone)
+	if err != nil {
+		return result, err
+	}
+	dst := a.ForZone(result.Zone.ID)
+
+	if opts.MFAProviders {
+		if err := cloneMFAProviders(src, dst, result); err != nil {
+			return result, err
+		}
+		if mfaConfig != nil {
+			updated := *result.Zone
+			updated.Config.MFAConfig = mfaConfig
+			if result.Zone, err = a.UpdateIdentityZone(updated); err != nil {
+				return result, err
+			}
+		}
+	}
+	if opts.IdentityProviders {
+		if err := cloneIdentityProviders(providers, dst, result); err != nil {
+			return result, err
+		}
+	}
+	if opts.Groups {
+		if err := cloneGroups(src, dst, result); err != nil {
+			return result, err
+		}
+	}
+	if opts.Clients {
+		if err := cloneClients(src, dst, result.Zone.Config.ClientSecretPolicy, opts.ClientSecrets, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// cloneZone returns the zone to create, and the MFA config to apply once the
+// zone's MFA providers exist.
+func cloneZone(source IdentityZone, newID string, subdomain string, opts CloneIdentityZoneOptions) (IdentityZone, *IdentityZoneMFAConfig, error) {
+	var config IdentityZoneConfig
+	j, err := json.Marshal(source.Config)
+	if err != nil {
+		return IdentityZone{}, nil, err
+	}
+	if err := json.Unmarshal(j, &config); err != nil {
+		return IdentityZone{}, nil, err
+	}
+
+	if saml := config.SAMLConfig; saml != nil {
+		for id, key := range saml.Keys {
+			if key.Key == "" {
+				delete(saml.Keys, id)
+			}
+		}
+		if _, ok := saml.Keys[saml.ActiveKeyID]; !ok {
+			saml.ActiveKeyID = ""
+		}
+	}
+	if policy := config.TokenPolicy; policy != nil {
+		if _, ok := policy.Keys[policy.ActiveKeyID]; !ok {
+			policy.ActiveKeyID = ""
+		}
+	}
+
+	if opts.Config != nil {
+		opts.Config(&config)
+	}
+
+	// The zone's MFA provider must exist in the zone before MFA can be
+	// configured, so MFA is configured after the providers are copied.
+	mfaConfig := config.MFAConfig
+	config.MFAConfig = nil
+
+	zone := IdentityZone{
+		ID:          newID,
+		Subdomain:   subdomain,
+		Name:        opts.Name,
+		Description: opts.Description,
+		Config:      config,
+	}
+	if zone.Name == "" {
+		zone.Name = subdomain
+	}
+	if zone.Description == "" {
+		zone.Description = source.Description
+	}
+	return zone, mfaConfig, nil
+}
+
+func cloneMFAProviders(src *API, dst *API, result *CloneIdentityZoneResult) error {
+	providers, err := src.ListMFAProviders()
+	if err != nil {
+		return err
+	}
+	for _, provider := range providers {
+		provider.ID = ""
+		provider.IdentityZoneID = dst.zoneID
+		provider.Created = 0
+		provider.LastModified = 0
+		created, err := dst.CreateMFAProvider(provider)
+		if err != nil {
+			return fmt.Errorf("copying MFA provider %v: %v", provider.Name, err)
+		}
+		result.MFAProviders = append(result.MFAProviders, *created)
+	}
+	return nil
+}
+
+// setIdentityProviderSecrets sets the secrets of the providers that need one
+// from secrets, keyed by origin key. It returns an error naming every provider
+// without a secret, so that none of them is copied without its secret.
+func setIdentityProviderSecrets(providers []IdentityProvider, secrets map[string]string) error {
+	var missing []string
+	for _, provider := range providers {
+		secret := secrets[provider.OriginKey]
+		switch c := provider.Config.(type) {
+		case *LDAPIdentityProviderConfig:
+			if secret != "" {
+				c.BindPassword = secret
+			}
+			if c.BindUserDN != "" && c.BindPassword == "" {
+				missing = append(missing, provider.OriginKey)
+			}
+		case *OIDCIdentityProviderConfig:
+			if secret != "" {
+				c.RelyingPartySecret = secret
+			}
+			if c.RelyingPartySecret == "" {
+				missing = append(missing, provider.OriginKey)
+			}
+		case *OAuth2IdentityProviderConfig:
+			if secret != "" {
+				c.RelyingPartySecret = secret
+			}
+			if c.RelyingPartySecret == "" {
+				missing = append(missing, provider.OriginKey)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("IdentityProviderSecrets has no secret for identity providers %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func cloneIdentityProviders(providers []IdentityProvider, dst *API, result *CloneIdentityZoneResult) error {
+	existing, err := dst.ListIdentityProviders()
+	if err != nil {
+		return err
+	}
+	existingByOrigin := map[string]IdentityProvider{}
+	for _, provider := range existing {
+		existingByOrigin[provider.OriginKey] = provider
+	}
+
+	for _, provider := range providers {
+		var copied *IdentityProvider
+		if current, ok := existingByOrigin[provider.OriginKey]; ok {
+			// Providers UAA creates with the zone, such as uaa, are
+			// updated in place.
+			current.Config = provider.Config
+			current.Active = provider.Active
+			copied, err = dst.UpdateIdentityProvider(current)
+		} else {
+			provider.ID = ""
+			provider.IdentityZoneID = dst.zoneID
+			provider.Version = 0
+			provider.AliasID = ""
+			provider.AliasZoneID = ""
+			provider.Created = 0
+			provider.LastModified = 0
+			copied, err = dst.CreateIdentityProvider(provider)
+		}
+		if err != nil {
+			return fmt.Errorf("copying identity provider %v: %v", provider.OriginKey, err)
+		}
+		result.IdentityProviders = append(result.IdentityProviders, *copied)
+	}
+	return nil
+}
+
+func cloneGroups(src *API, dst *API, result *CloneIdentityZoneResult) error {
+	groups, err := src.ListAllGroups("", "", "", "")
+	if err != nil {
+		return err
+	}
+	existing, err := dst.ListAllGroups("", "", "displayName", "")
+	if err != nil {
+		return err
+	}
+	existingNames := map[string]bool{}
+	for _, group := range existing {
+		existingNames[group.DisplayName] = true
+	}
+
+	for _, group := range groups {
+		if existingNames[group.DisplayName] {
+			continue
+		}
+		created, err := dst.CreateGroup(Group{
+			DisplayName: group.DisplayName,
+			Description: group.Description,
+			ZoneID:      dst.zoneID,
+		})
+		if err != nil {
+			return fmt.Errorf("copying group %v: %v", group.DisplayName, err)
+		}
+		result.Groups = append(result.Groups, *created)
+	}
+	return nil
+}
+
+func cloneClients(src *API, dst *API, policy *ClientSecretPolicy, secrets map[string]string, result *CloneIdentityZoneResult) error {
+	clients, err := src.ListAllClients("", "", "")
+	if err != nil {
+		return err
+	}
+	for _, client := range clients {
+		client.LastModified = 0
+		client.ClientSecret = ""
+		if secret, ok := secrets[client.ClientID]; ok {
+			client.ClientSecret = secret
+		} else if needsClientSecret(client) {
+			if client.ClientSecret, err = generateClientSecret(policy); err != nil {
+				return err
+			}
+		}
+		created, err := dst.CreateClient(client)
+		if err != nil {
+			return fmt.Errorf("copying client %v: %v", client.ClientID, err)
+		}
+		if client.ClientSecret != "" {
+			result.ClientSecrets[client.ClientID] = client.ClientSecret
+		}
+		result.Clients = append(result.Clients, *created)
+	}
+	return nil
+}
+
+func needsClientSecret(c Client) bool {
+	for _, grant := range c.AuthorizedGrantTypes {
+		if grant != string(IMPLICIT) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	secretUpperCaseCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	secretLowerCaseCharacters = "abcdefghijklmnopqrstuvwxyz"
+	secretDigits              = "0123456789"
+	secretSpecialCharacters   = "-_.~"
+	defaultSecretLength       = 32
+)
+
+// checkClientSecretPolicy returns an error if no secret can satisfy the given
+// policy, which may be nil, because it requires more characters than its
+// maximum length allows.
+func checkClientSecretPolicy(policy *ClientSecretPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	required := policy.RequireUpperCaseCharacter + policy.RequireLowerCaseCharacter + policy.RequireDigit + policy.RequireSpecialCharacter
+	if policy.MaxLength > 0 && required > policy.MaxLength {
+		return fmt.Errorf("the client secret policy requires %v characters but has a maximum length of %v", required, policy.MaxLength)
+	}
+	if policy.MaxLength > 0 && policy.MinLength > policy.MaxLength {
+		return fmt.Errorf("the client secret policy has a minimum length of %v but a maximum length of %v", policy.MinLength, policy.MaxLength)
+	}
+	return nil
+}
+
+// generateClientSecret returns a random secret that satisfies the given
+// policy, which may be nil.
+func generateClientSecret(policy *ClientSecretPolicy) (string, error) {
+	if err := checkClientSecretPolicy(policy); err != nil {
+		return "", err
+	}
+	p := ClientSecretPolicy{}
+	if policy != nil {
+		p = *policy
+	}
+	length := defaultSecretLength
+	if p.MinLength > length {
+		length = p.MinLength
+	}
+	if p.MaxLength > 0 && p.MaxLength < length {
+		length = p.MaxLength
+	}
+
+	var secret []byte
+	appendRandom := func(charset string, n int) error {
+		for i := 0; i < n; i++ {
+			j, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+			if err != nil {
+				return err
+			}
+			secret = append(secret, charset[j.Int64()])
+		}
+		return nil
+	}
+	required := []struct {
+		charset string
+		count   int
+	}{
+		{secretUpperCaseCharacters, p.RequireUpperCaseCharacter},
+		{secretLowerCaseCharacters, p.RequireLowerCaseCharacter},
+		{secretDigits, p.RequireDigit},
+		{secretSpecialCharacters, p.RequireSpecialCharacter},
+	}
+	for _, r := range required {
+		if err := appendRandom(r.charset, r.count); err != nil {
+			return "", err
+		}
+	}
+	all := secretUpperCaseCharacters + secretLowerCaseCharacters + secretDigits
+	if err := appendRandom(all, length-len(secret)); err != nil {
+		return "", err
+	}
+
+	for i := len(secret) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		secret[i], secret[j.Int64()] = secret[j.Int64()], secret[i]
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,228 @@
+package uaa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"unicode"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testCloneIdentityZone(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s           *httptest.Server
+		a           *uaa.API
+		requests    []string
+		bodies      map[string]string
+		clientPosts []string
+	)
+
+	sourceZone := `{
+		"id": "source",
+		"subdomain": "source",
+		"name": "Source",
+		"description": "the source zone",
+		"version": 3,
+		"config": {
+			"clientSecretPolicy": {"minLength": 12, "maxLength": 24, "requireUpperCaseCharacter": 2, "requireDigit": 2, "requireSpecialCharacter": 1},
+			"tokenPolicy": {"accessTokenValidity": 3600, "activeKeyId": "token-key"},
+			"samlConfig": {"activeKeyId": "saml-key", "keys": {"saml-key": {"certificate": "cert"}}},
+			"branding": {"companyName": "Example"},
+			"mfaConfig": {"enabled": true, "providerName": "mfa"}
+		}
+	}`
+
+	respond := func(w http.ResponseWriter, status int, body string) {
+		w.WriteHeader(status)
+		_, err := w.Write([]byte(body))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		requests = nil
+		bodies = map[string]string{}
+		clientPosts = nil
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			zone := req.Header.Get("X-Identity-Zone-Id")
+			key := req.Method + " " + req.URL.Path + " @" + zone
+			requests = append(requests, key)
+			body, _ := ioutil.ReadAll(req.Body)
+			bodies[key] = string(body)
+
+			switch key {
+			case "GET /identity-zones/source @":
+				respond(w, http.StatusOK, sourceZone)
+			case "POST /identity-zones @":
+				respond(w, http.StatusCreated, string(body))
+			case "PUT /identity-zones/tenant @":
+				respond(w, http.StatusOK, string(body))
+			case "GET /mfa-providers @source":
+				respond(w, http.StatusOK, `[{"id": "mfa-id", "name": "mfa", "type": "google-authenticator", "identityZoneId": "source", "config": {"issuer": "uaa"}}]`)
+			case "POST /mfa-providers @tenant":
+				respond(w, http.StatusCreated, string(body))
+			case "GET /identity-providers @source":
+				respond(w, http.StatusOK, `[
+					{"id": "source-uaa", "originKey": "uaa", "name": "uaa", "type": "uaa", "active": true, "config": {"lockoutPolicy": {"lockoutAfterFailures": 3}}},
+					{"id": "source-oidc", "originKey": "okta", "name": "Okta", "type": "oidc1.0", "active": true, "identityZoneId": "source", "config": {"authUrl": "https://okta.example.com/authorize", "relyingPartyId": "uaa"}}
+				]`)
+			case "GET /identity-providers @tenant":
+				respond(w, http.StatusOK, `[{"id": "tenant-uaa", "originKey": "uaa", "name": "uaa", "type": "uaa", "active": true, "identityZoneId": "tenant"}]`)
+			case "PUT /identity-providers/tenant-uaa @tenant", "POST /identity-providers @tenant":
+				respond(w, http.StatusOK, string(body))
+			case "GET /Groups @source":
+				respond(w, http.StatusOK, PaginatedResponse(
+					uaa.Group{ID: "g1", DisplayName: "uaa.user"},
+					uaa.Group{ID: "g2", DisplayName: "app.admin", Description: "app admins", Members: []uaa.GroupMember{{Value: "user-1"}}},
+				))
+			case "GET /Groups @tenant":
+				respond(w, http.StatusOK, PaginatedResponse(uaa.Group{ID: "t1", DisplayName: "uaa.user"}))
+			case "POST /Groups @tenant":
+				respond(w, http.StatusCreated, string(body))
+			case "GET /oauth/clients @source":
+				respond(w, http.StatusOK, PaginatedResponse(
+					uaa.Client{ClientID: "app", AuthorizedGrantTypes: []string{"authorization_code"}, RedirectURI: []string{"https://app.example.com"}},
+					uaa.Client{ClientID: "service", AuthorizedGrantTypes: []string{"client_credentials"}},
+					uaa.Client{ClientID: "spa", AuthorizedGrantTypes: []string{"implicit"}, RedirectURI: []string{"https://spa.example.com"}},
+				))
+			case "POST /oauth/clients @tenant":
+				clientPosts = append(clientPosts, string(body))
+				respond(w, http.StatusCreated, string(body))
+			case "GET /identity-zones/missing @":
+				respond(w, http.StatusNotFound, `{"error": "not_found"}`)
+			default:
+				t.Errorf("unexpected request %v", key)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	it("creates the new zone with a copy of the source zone's config", func() {
+		result, err := a.CloneIdentityZone("source", "tenant", "tenant", uaa.CloneIdentityZoneOptions{
+			Config: func(config *uaa.IdentityZoneConfig) {
+				config.Branding.CompanyName = "Tenant"
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{"GET /identity-zones/source @", "POST /identity-zones @"}))
+		Expect(bodies["POST /identity-zones @"]).To(MatchJSON(`{
+			"id": "tenant",
+			"subdomain": "tenant",
+			"name": "tenant",
+			"description": "the source zone",
+			"config": {
+				"clientSecretPolicy": {"minLength": 12, "maxLength": 24, "requireUpperCaseCharacter": 2, "requireDigit": 2, "requireSpecialCharacter": 1},
+				"tokenPolicy": {"accessTokenValidity": 3600},
+				"samlConfig": {},
+				"branding": {"companyName": "Tenant"}
+			}
+		}`))
+		Expect(result.Zone.ID).To(Equal("tenant"))
+	})
+
+	it("keeps the active token signing key when opts.Config supplies it", func() {
+		_, err := a.CloneIdentityZone("source", "tenant", "tenant", uaa.CloneIdentityZoneOptions{
+			Config: func(config *uaa.IdentityZoneConfig) {
+				config.TokenPolicy.ActiveKeyID = "tenant-key"
+				config.TokenPolicy.Keys = map[string]uaa.TokenPolicyKey{"tenant-key": {SigningKey: "pem"}}
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		var zone uaa.IdentityZone
+		Expect(json.Unmarshal([]byte(bodies["POST /identity-zones @"]), &zone)).To(Succeed())
+		Expect(zone.Config.TokenPolicy.ActiveKeyID).To(Equal("tenant-key"))
+		Expect(zone.Config.TokenPolicy.Keys).To(HaveKey("tenant-key"))
+	})
+
+	it("copies the selected resources into the new zone", func() {
+		result, err := a.CloneIdentityZone("source", "tenant", "tenant", uaa.CloneIdentityZoneOptions{
+			Name:                    "Tenant",
+			Clients:                 true,
+			Groups:                  true,
+			IdentityProviders:       true,
+			MFAProviders:            true,
+			ClientSecrets:           map[string]string{"app": "supplied-secret"},
+			IdentityProviderSecrets: map[string]string{"okta": "okta-secret"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		decode := func(body string) map[string]interface{} {
+			var v map[string]interface{}
+			Expect(json.Unmarshal([]byte(body), &v)).To(Succeed())
+			return v
+		}
+
+		Expect(bodies["POST /mfa-providers @tenant"]).To(MatchJSON(`{"name": "mfa", "type": "google-authenticator", "identityZoneId": "tenant", "config": {"issuer": "uaa"}}`))
+		Expect(decode(bodies["PUT /identity-zones/tenant @"])["config"]).To(HaveKeyWithValue("mfaConfig", map[string]interface{}{"enabled": true, "providerName": "mfa"}))
+		Expect(result.MFAProviders).To(HaveLen(1))
+
+		Expect(bodies["PUT /identity-providers/tenant-uaa @tenant"]).To(MatchJSON(`{"id": "tenant-uaa", "originKey": "uaa", "name": "uaa", "type": "uaa", "active": true, "identityZoneId": "tenant", "config": {"lockoutPolicy": {"lockoutPeriodSeconds": 0, "lockoutAfterFailures": 3, "countFailuresWithin": 0}}}`))
+		Expect(bodies["POST /identity-providers @tenant"]).To(MatchJSON(`{"originKey": "okta", "name": "Okta", "type": "oidc1.0", "active": true, "identityZoneId": "tenant", "config": {"authUrl": "https://okta.example.com/authorize", "relyingPartyId": "uaa", "relyingPartySecret": "okta-secret"}}`))
+		Expect(result.IdentityProviders).To(HaveLen(2))
+
+		Expect(bodies["POST /Groups @tenant"]).To(MatchJSON(`{"displayName": "app.admin", "description": "app admins", "zoneId": "tenant"}`))
+		Expect(result.Groups).To(HaveLen(1))
+
+		Expect(result.Clients).To(HaveLen(3))
+		Expect(result.ClientSecrets).To(HaveKeyWithValue("app", "supplied-secret"))
+		Expect(result.ClientSecrets).NotTo(HaveKey("spa"))
+		generated := result.ClientSecrets["service"]
+		Expect(len(generated)).To(Equal(24))
+		var upper, digit, special int
+		for _, r := range generated {
+			switch {
+			case unicode.IsUpper(r):
+				upper++
+			case unicode.IsDigit(r):
+				digit++
+			case !unicode.IsLetter(r):
+				special++
+			}
+		}
+		Expect(upper).To(BeNumerically(">=", 2))
+		Expect(digit).To(BeNumerically(">=", 2))
+		Expect(special).To(BeNumerically(">=", 1))
+		Expect(clientPosts).To(HaveLen(3))
+		Expect(decode(clientPosts[0])).To(HaveKeyWithValue("client_secret", "supplied-secret"))
+		Expect(decode(clientPosts[1])).To(HaveKeyWithValue("client_secret", generated))
+		Expect(decode(clientPosts[2])).NotTo(HaveKey("client_secret"))
+	})
+
+	it("creates nothing when an identity provider has no secret", func() {
+		result, err := a.CloneIdentityZone("source", "tenant", "tenant", uaa.CloneIdentityZoneOptions{IdentityProviders: true})
+		Expect(err).To(MatchError("IdentityProviderSecrets has no secret for identity providers okta"))
+		Expect(result.Zone).To(BeNil())
+		Expect(requests).To(Equal([]string{"GET /identity-zones/source @", "GET /identity-providers @source"}))
+	})
+
+	it("creates nothing when the client secret policy cannot be satisfied", func() {
+		result, err := a.CloneIdentityZone("source", "tenant", "tenant", uaa.CloneIdentityZoneOptions{
+			Clients: true,
+			Config: func(config *uaa.IdentityZoneConfig) {
+				config.ClientSecretPolicy.MaxLength = 4
+			},
+		})
+		Expect(err).To(MatchError("the client secret policy requires 5 characters but has a maximum length of 4"))
+		Expect(result.Zone).To(BeNil())
+		Expect(requests).To(Equal([]string{"GET /identity-zones/source @"}))
+	})
+
+	it("stops at the first error", func() {
+		result, err := a.CloneIdentityZone("missing", "tenant", "tenant", uaa.CloneIdentityZoneOptions{Clients: true})
+		Expect(err).To(HaveOccurred())
+		Expect(result.Zone).To(BeNil())
+		Expect(requests).To(Equal([]string{"GET /identity-zones/missing @"}))
+	})
+}
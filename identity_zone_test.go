@@ -1,6 +1,16 @@
 package uaa_test
 
-import uaa "github.com/cloudfoundry-community/go-uaa"
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
 
 var identityzoneResponse string = `{
   "id" : "00000000-0000-0000-0000-000000000001",
@@ -375,3 +385,191 @@ var testIdentityZoneJSON string = `{
   "config": {},
   "name" : "The Twiglet Zone"
 }`
+
+var identityzoneWithUnknownFieldsResponse string = `{
+  "id" : "twiglet",
+  "subdomain" : "twiglet",
+  "name" : "The Twiglet Zone",
+  "version" : 3,
+  "futureZoneField" : "kept",
+  "config" : {
+    "tokenPolicy" : {
+      "accessTokenValidity" : 3600,
+      "futurePolicyField" : { "a" : [ 1, 2 ] },
+      "keys" : { "key-1" : { "signingKey" : "pem", "futureKeyField" : 1 } }
+    },
+    "samlConfig" : {
+      "keys" : { "saml-1" : { "certificate" : "cert", "futureSamlKeyField" : 1 } }
+    },
+    "corsPolicy" : {
+      "xhrConfiguration" : { "allowedOrigins" : [ ".*" ], "futureCorsField" : 1 }
+    },
+    "links" : {
+      "logout" : { "redirectUrl" : "/login", "futureLogoutField" : 1 },
+      "selfService" : { "signup" : "/create_account", "futureSelfServiceField" : 1 }
+    },
+    "prompts" : [ { "name" : "username", "type" : "text", "text" : "Email", "futurePromptField" : 1 } ],
+    "branding" : {
+      "banner" : { "text" : "Hello", "futureBannerField" : 1 },
+      "consent" : { "text" : "Policy", "futureConsentField" : 1 }
+    },
+    "userConfig" : {
+      "defaultGroups" : [ "openid" ],
+      "allowedGroups" : [ "cloud_controller.read" ],
+      "maxUsers" : -1,
+      "futureUserField" : true
+    },
+    "mfaConfig" : {
+      "enabled" : true,
+      "providerName" : "mfaprovider",
+      "identityProviders" : [ "uaa", "ldap" ]
+    },
+    "defaultIdentityProvider" : "ldap",
+    "futureConfigField" : 42
+  }
+}`
+
+func testIdentityZonesExtra(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("decoding identity zones", func() {
+		it("decodes the full zone config", func() {
+			var zone uaa.IdentityZone
+			Expect(json.Unmarshal([]byte(identityzoneResponse), &zone)).To(Succeed())
+			Expect(zone.Config.Issuer).To(Equal("http://localhost:8080/uaa"))
+			Expect(zone.Config.SAMLConfig.EntityID).To(Equal("cloudfoundry-saml-login"))
+			Expect(zone.Config.SAMLConfig.Extra).To(HaveKey("certificate"))
+			Expect(zone.Config.Branding.FooterLegalText).To(Equal("Test footer legal text"))
+			Expect(zone.Config.Branding.FooterLinks).To(HaveKeyWithValue("Support", "http://support.example.com"))
+			Expect(zone.Config.Branding.Banner.TextColor).To(Equal("#000000"))
+			Expect(*zone.Config.Branding.Consent).To(Equal(uaa.Consent{Text: "Some Policy", Link: "http://policy.example.com"}))
+			Expect(zone.Extra).To(BeNil())
+			Expect(zone.Config.Extra).To(BeNil())
+		})
+
+		it("decodes user and MFA config and keeps unknown members", func() {
+			var zone uaa.IdentityZone
+			Expect(json.Unmarshal([]byte(identityzoneWithUnknownFieldsResponse), &zone)).To(Succeed())
+			Expect(zone.Config.DefaultIdentityProvider).To(Equal("ldap"))
+			Expect(zone.Config.UserConfig.AllowedGroups).To(Equal([]string{"cloud_controller.read"}))
+			Expect(*zone.Config.UserConfig.MaxUsers).To(Equal(int64(-1)))
+			Expect(zone.Config.MFAConfig.IdentityProviders).To(Equal([]string{"uaa", "ldap"}))
+			Expect(zone.Extra).To(HaveKey("futureZoneField"))
+			Expect(zone.Config.Extra).To(HaveKey("futureConfigField"))
+			Expect(zone.Config.TokenPolicy.Extra).To(HaveKey("futurePolicyField"))
+			Expect(zone.Config.UserConfig.Extra).To(HaveKey("futureUserField"))
+			Expect(zone.Config.TokenPolicy.Keys["key-1"].Extra).To(HaveKey("futureKeyField"))
+			Expect(zone.Config.CORSPolicy.XHRConfiguration.Extra).To(HaveKey("futureCorsField"))
+			Expect(zone.Config.Links.Logout.Extra).To(HaveKey("futureLogoutField"))
+			Expect(zone.Config.Links.SelfService.Extra).To(HaveKey("futureSelfServiceField"))
+			Expect(zone.Config.Prompts[0].Extra).To(HaveKey("futurePromptField"))
+			Expect(zone.Config.Branding.Banner.Extra).To(HaveKey("futureBannerField"))
+		})
+
+		it("round-trips an empty allowed groups list and a zero user limit", func() {
+			var config uaa.IdentityZoneUserConfig
+			Expect(json.Unmarshal([]byte(`{"allowedGroups":[],"maxUsers":0}`), &config)).To(Succeed())
+			Expect(config.AllowedGroups).To(BeEmpty())
+			Expect(config.AllowedGroups).NotTo(BeNil())
+			Expect(config.AllowsGroup("scim.read")).To(BeFalse())
+			Expect(*config.MaxUsers).To(BeZero())
+			j, err := json.Marshal(config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"allowedGroups":[],"maxUsers":0}`))
+
+			j, err = json.Marshal(uaa.IdentityZoneUserConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{}`))
+		})
+
+		it("round-trips signing and self-service settings turned off", func() {
+			raw := `{"samlConfig":{"assertionSigned":false,"requestSigned":false,"wantAssertionSigned":false},"links":{"selfService":{"selfServiceLinksEnabled":false}}}`
+			var config uaa.IdentityZoneConfig
+			Expect(json.Unmarshal([]byte(raw), &config)).To(Succeed())
+			Expect(*config.SAMLConfig.AssertionSigned).To(BeFalse())
+			Expect(*config.Links.SelfService.SelfServiceLinksEnabled).To(BeFalse())
+			j, err := json.Marshal(config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(raw))
+
+			j, err = json.Marshal(uaa.IdentityZoneLinks{HomeRedirect: "/home"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"homeRedirect":"/home"}`))
+		})
+
+		it("keeps the service provider configuration as UAA returned it", func() {
+			var config uaa.IdentityZoneConfig
+			Expect(json.Unmarshal([]byte(`{"serviceProvider":{"entityId":"sp","signRequests":true}}`), &config)).To(Succeed())
+			Expect(config.ServiceProvider).To(MatchJSON(`{"entityId":"sp","signRequests":true}`))
+			Expect(config.Extra).To(BeNil())
+			j, err := json.Marshal(config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"serviceProvider":{"entityId":"sp","signRequests":true}}`))
+		})
+
+		it("does not let Extra override modelled members", func() {
+			zone := uaa.IdentityZone{
+				Subdomain: "twiglet",
+				Name:      "The Twiglet Zone",
+				Extra:     map[string]json.RawMessage{"name": json.RawMessage(`"other"`), "new": json.RawMessage(`1`)},
+			}
+			j, err := json.Marshal(zone)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(j).To(MatchJSON(`{"subdomain":"twiglet","name":"The Twiglet Zone","config":{},"new":1}`))
+		})
+	})
+
+	when("a zone is read and then updated", func() {
+		it("sends back every member UAA returned", func() {
+			var body []byte
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodPut {
+					body, _ = ioutil.ReadAll(req.Body)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(identityzoneWithUnknownFieldsResponse))
+			})
+			zone, err := a.GetIdentityZone("twiglet")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = a.UpdateIdentityZone(*zone)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(2))
+			Expect(body).To(MatchJSON(identityzoneWithUnknownFieldsResponse))
+		})
+	})
+
+	when("checking allowed groups", func() {
+		it("allows every group when AllowedGroups is nil", func() {
+			Expect(uaa.IdentityZoneUserConfig{}.AllowsGroup("anything")).To(BeTrue())
+		})
+
+		it("allows only the allowed and default groups otherwise", func() {
+			c := uaa.IdentityZoneUserConfig{DefaultGroups: []string{"openid"}, AllowedGroups: []string{"scim.read"}}
+			Expect(c.AllowsGroup("scim.read")).To(BeTrue())
+			Expect(c.AllowsGroup("openid")).To(BeTrue())
+			Expect(c.AllowsGroup("scim.write")).To(BeFalse())
+		})
+	})
+}
@@ -1,5 +1,10 @@
 package uaa
 
+import (
+	"encoding/json"
+	"errors"
+)
+
 // IdentityZonesEndpoint is the path to the users resource.
 const IdentityZonesEndpoint string = "/identity-zones"
 
@@ -14,6 +19,8 @@ type IdentityZone struct {
 	Description  string             `json:"description,omitempty"`
 	Created      int                `json:"created,omitempty"`
 	LastModified int                `json:"last_modified,omitempty"`
+	// Extra holds the members UAA returned that IdentityZone does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Identifier returns the field used to uniquely identify an IdentityZone.
@@ -21,6 +28,19 @@ func (iz IdentityZone) Identifier() string {
 	return iz.ID
 }
 
+// identityZone retrieves the zone with the given ID or, when zoneID is
+// empty, the zone the API was created for with ForZone or WithZoneID. It
+// requires the zones.read or zones.<zone id>.read scope.
+func (a *API) identityZone(zoneID string) (*IdentityZone, error) {
+	if zoneID == "" {
+		zoneID = a.zoneID
+	}
+	if zoneID == "" {
+		return nil, errors.New("zoneID cannot be blank when the API is not for a zone")
+	}
+	return a.GetIdentityZone(zoneID)
+}
+
 // ClientSecretPolicy is an identity zone client secret policy.
 type ClientSecretPolicy struct {
 	MinLength                 int `json:"minLength,omitempty"`
@@ -29,6 +49,9 @@ type ClientSecretPolicy struct {
 	RequireLowerCaseCharacter int `json:"requireLowerCaseCharacter,omitempty"`
 	RequireDigit              int `json:"requireDigit,omitempty"`
 	RequireSpecialCharacter   int `json:"requireSpecialCharacter,omitempty"`
+	// Extra holds the members UAA returned that ClientSecretPolicy does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // TokenPolicy is an identity zone token policy.
@@ -39,6 +62,20 @@ type TokenPolicy struct {
 	RefreshTokenUnique   bool   `json:"refreshTokenUnique,omitempty"`
 	RefreshTokenFormat   string `json:"refreshTokenFormat,omitempty"`
 	ActiveKeyID          string `json:"activeKeyId,omitempty"`
+	// Keys are the zone's token signing keys, keyed by key ID. UAA does not
+	// return them when a zone is read.
+	Keys map[string]TokenPolicyKey `json:"keys,omitempty"`
+	// Extra holds the members UAA returned that TokenPolicy does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// TokenPolicyKey is an identity zone token signing key.
+type TokenPolicyKey struct {
+	SigningKey  string `json:"signingKey,omitempty"`
+	SigningCert string `json:"signingCert,omitempty"`
+	SigningAlg  string `json:"signingAlg,omitempty"`
+	// Extra holds the members UAA returned that TokenPolicyKey does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // SAMLKey is an identity zone SAML key.
@@ -46,58 +83,86 @@ type SAMLKey struct {
 	Key         string `json:"key,omitempty"`
 	Passphrase  string `json:"passphrase,omitempty"`
 	Certificate string `json:"certificate,omitempty"`
+	// Extra holds the members UAA returned that SAMLKey does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
-// SAMLConfig is an identity zone SAMLConfig.
+// SAMLConfig is an identity zone SAMLConfig, which configures UAA as a SAML
+// service provider and as a SAML identity provider for the zone.
 type SAMLConfig struct {
-	AssertionSigned            bool               `json:"assertionSigned,omitempty"`
-	RequestSigned              bool               `json:"requestSigned,omitempty"`
-	WantAssertionSigned        bool               `json:"wantAssertionSigned,omitempty"`
+	// EntityID is the zone's SAML entity ID as a service provider.
+	EntityID string `json:"entityID,omitempty"`
+	// AssertionSigned, RequestSigned and WantAssertionSigned are pointers
+	// because UAA treats a missing value as true.
+	AssertionSigned            *bool              `json:"assertionSigned,omitempty"`
+	RequestSigned              *bool              `json:"requestSigned,omitempty"`
+	WantAssertionSigned        *bool              `json:"wantAssertionSigned,omitempty"`
 	WantAuthnRequestSigned     bool               `json:"wantAuthnRequestSigned,omitempty"`
 	AssertionTimeToLiveSeconds int                `json:"assertionTimeToLiveSeconds,omitempty"`
 	ActiveKeyID                string             `json:"activeKeyId,omitempty"`
 	Keys                       map[string]SAMLKey `json:"keys,omitempty"`
 	DisableInResponseToCheck   bool               `json:"disableInResponseToCheck,omitempty"`
+	// Extra holds the members UAA returned that SAMLConfig does not model,
+	// such as the legacy certificate and privateKey.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // CORSPolicy is an identity zone CORSPolicy.
 type CORSPolicy struct {
-	XHRConfiguration struct {
-		AllowedOrigins        []string      `json:"allowedOrigins,omitempty"`
-		AllowedOriginPatterns []interface{} `json:"allowedOriginPatterns,omitempty"`
-		AllowedURIs           []string      `json:"allowedUris,omitempty"`
-		AllowedURIPatterns    []interface{} `json:"allowedUriPatterns,omitempty"`
-		AllowedHeaders        []string      `json:"allowedHeaders,omitempty"`
-		AllowedMethods        []string      `json:"allowedMethods,omitempty"`
-		AllowedCredentials    bool          `json:"allowedCredentials,omitempty"`
-		MaxAge                int           `json:"maxAge,omitempty"`
-	} `json:"xhrConfiguration,omitempty"`
-	DefaultConfiguration struct {
-		AllowedOrigins        []string      `json:"allowedOrigins,omitempty"`
-		AllowedOriginPatterns []interface{} `json:"allowedOriginPatterns,omitempty"`
-		AllowedURIs           []string      `json:"allowedUris,omitempty"`
-		AllowedURIPatterns    []interface{} `json:"allowedUriPatterns,omitempty"`
-		AllowedHeaders        []string      `json:"allowedHeaders,omitempty"`
-		AllowedMethods        []string      `json:"allowedMethods,omitempty"`
-		AllowedCredentials    bool          `json:"allowedCredentials,omitempty"`
-		MaxAge                int           `json:"maxAge,omitempty"`
-	} `json:"defaultConfiguration,omitempty"`
+	XHRConfiguration     *CORSConfiguration `json:"xhrConfiguration,omitempty"`
+	DefaultConfiguration *CORSConfiguration `json:"defaultConfiguration,omitempty"`
+	// Extra holds the members UAA returned that CORSPolicy does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// CORSConfiguration is the CORS configuration for one kind of request to an
+// identity zone.
+type CORSConfiguration struct {
+	AllowedOrigins        []string      `json:"allowedOrigins,omitempty"`
+	AllowedOriginPatterns []interface{} `json:"allowedOriginPatterns,omitempty"`
+	AllowedURIs           []string      `json:"allowedUris,omitempty"`
+	AllowedURIPatterns    []interface{} `json:"allowedUriPatterns,omitempty"`
+	AllowedHeaders        []string      `json:"allowedHeaders,omitempty"`
+	AllowedMethods        []string      `json:"allowedMethods,omitempty"`
+	AllowedCredentials    bool          `json:"allowedCredentials,omitempty"`
+	MaxAge                int           `json:"maxAge,omitempty"`
+	// Extra holds the members UAA returned that CORSConfiguration does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // IdentityZoneLinks is an identity zone link.
 type IdentityZoneLinks struct {
-	Logout struct {
-		RedirectURL              string   `json:"redirectUrl,omitempty"`
-		RedirectParameterName    string   `json:"redirectParameterName,omitempty"`
-		DisableRedirectParameter bool     `json:"disableRedirectParameter,omitempty"`
-		Whitelist                []string `json:"whitelist,omitempty"`
-	} `json:"logout,omitempty"`
-	HomeRedirect string `json:"homeRedirect,omitempty"`
-	SelfService  struct {
-		SelfServiceLinksEnabled bool   `json:"selfServiceLinksEnabled,omitempty"`
-		Signup                  string `json:"signup,omitempty"`
-		Passwd                  string `json:"passwd,omitempty"`
-	} `json:"selfService,omitempty"`
+	Logout       *LogoutLinks      `json:"logout,omitempty"`
+	HomeRedirect string            `json:"homeRedirect,omitempty"`
+	SelfService  *SelfServiceLinks `json:"selfService,omitempty"`
+	// Extra holds the members UAA returned that IdentityZoneLinks does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LogoutLinks configures where an identity zone redirects users after they
+// log out.
+type LogoutLinks struct {
+	RedirectURL              string   `json:"redirectUrl,omitempty"`
+	RedirectParameterName    string   `json:"redirectParameterName,omitempty"`
+	DisableRedirectParameter bool     `json:"disableRedirectParameter,omitempty"`
+	Whitelist                []string `json:"whitelist,omitempty"`
+	// Extra holds the members UAA returned that LogoutLinks does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// SelfServiceLinks configures the sign up and password reset links on an
+// identity zone's login page.
+type SelfServiceLinks struct {
+	// SelfServiceLinksEnabled is a pointer because UAA treats a missing
+	// value as true.
+	SelfServiceLinksEnabled *bool  `json:"selfServiceLinksEnabled,omitempty"`
+	Signup                  string `json:"signup,omitempty"`
+	Passwd                  string `json:"passwd,omitempty"`
+	// Extra holds the members UAA returned that SelfServiceLinks does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Prompt is a UAA prompt.
@@ -105,24 +170,81 @@ type Prompt struct {
 	Name string `json:"name,omitempty"`
 	Type string `json:"type,omitempty"`
 	Text string `json:"text,omitempty"`
+	// Extra holds the members UAA returned that Prompt does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Branding is the branding for a UAA identity zone.
 type Branding struct {
-	CompanyName string `json:"companyName,omitempty"`
-	ProductLogo string `json:"productLogo,omitempty"`
-	SquareLogo  string `json:"squareLogo,omitempty"`
+	CompanyName     string `json:"companyName,omitempty"`
+	ProductLogo     string `json:"productLogo,omitempty"`
+	SquareLogo      string `json:"squareLogo,omitempty"`
+	FooterLegalText string `json:"footerLegalText,omitempty"`
+	// FooterLinks maps the text of each link in the login page footer to
+	// its URL.
+	FooterLinks map[string]string `json:"footerLinks,omitempty"`
+	Banner      *Banner           `json:"banner,omitempty"`
+	// Consent, when set, is a policy users must agree to before signing up.
+	Consent *Consent `json:"consent,omitempty"`
+	// Extra holds the members UAA returned that Branding does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Banner is an announcement shown at the top of an identity zone's login
+// page.
+type Banner struct {
+	Logo            string `json:"logo,omitempty"`
+	Text            string `json:"text,omitempty"`
+	TextColor       string `json:"textColor,omitempty"`
+	BackgroundColor string `json:"backgroundColor,omitempty"`
+	Link            string `json:"link,omitempty"`
+	// Extra holds the members UAA returned that Banner does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Consent is the text of a policy users must agree to, and a link to the
+// full policy.
+type Consent struct {
+	Text string `json:"text,omitempty"`
+	Link string `json:"link,omitempty"`
+	// Extra holds the members UAA returned that Consent does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // IdentityZoneUserConfig is the user configuration for an identity zone.
 type IdentityZoneUserConfig struct {
+	// DefaultGroups are the groups every user in the zone is a member of.
+	// UAA adds them to a user's token without recording the memberships.
 	DefaultGroups []string `json:"defaultGroups,omitempty"`
+	// AllowedGroups, when not nil, restricts the groups users in the zone
+	// can be members of to these and the DefaultGroups. An empty, non-nil
+	// slice allows only the DefaultGroups.
+	AllowedGroups []string `json:"allowedGroups,omitzero"`
+	// MaxUsers is the maximum number of users in the zone. When it is nil
+	// UAA's default of -1, meaning there is no limit, applies.
+	MaxUsers           *int64 `json:"maxUsers,omitempty"`
+	CheckOriginEnabled bool   `json:"checkOriginEnabled,omitempty"`
+	// Extra holds the members UAA returned that IdentityZoneUserConfig does
+	// not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// AllowsGroup reports whether users in the zone can be members of the group
+// with the given name.
+func (c IdentityZoneUserConfig) AllowsGroup(name string) bool {
+	return c.AllowedGroups == nil || contains(c.AllowedGroups, name) || contains(c.DefaultGroups, name)
 }
 
 // IdentityZoneMFAConfig is the MFA configuration for an identity zone.
 type IdentityZoneMFAConfig struct {
 	Enabled      *bool  `json:"enabled,omitempty"`
 	ProviderName string `json:"providerName,omitempty"`
+	// IdentityProviders are the origin keys of the identity providers whose
+	// users must use MFA, or DefaultMFAIdentityProviders when it is empty.
+	IdentityProviders []string `json:"identityProviders,omitempty"`
+	// Extra holds the members UAA returned that IdentityZoneMFAConfig does
+	// not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // IdentityZoneConfig is the configuration for an identity zone.
@@ -138,4 +260,185 @@ type IdentityZoneConfig struct {
 	AccountChooserEnabled *bool                   `json:"accountChooserEnabled,omitempty"`
 	UserConfig            *IdentityZoneUserConfig `json:"userConfig,omitempty"`
 	MFAConfig             *IdentityZoneMFAConfig  `json:"mfaConfig,omitempty"`
+	// Issuer overrides the issuer of the tokens the zone issues.
+	Issuer string `json:"issuer,omitempty"`
+	// DefaultIdentityProvider is the origin key of the identity provider the
+	// login page uses when a user does not choose one.
+	DefaultIdentityProvider string `json:"defaultIdentityProvider,omitempty"`
+	// ServiceProvider is the zone's service provider configuration. UAA does
+	// not document its members, so it is kept exactly as UAA returned it.
+	ServiceProvider json.RawMessage `json:"serviceProvider,omitempty"`
+	// Extra holds the members UAA returned that IdentityZoneConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the ClientSecretPolicy, including its Extra members.
+func (p ClientSecretPolicy) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(p)
+}
+
+// UnmarshalJSON decodes the ClientSecretPolicy, keeping unmodelled members in Extra.
+func (p *ClientSecretPolicy) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, p)
+}
+
+// MarshalJSON encodes the TokenPolicy, including its Extra members.
+func (p TokenPolicy) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(p)
+}
+
+// UnmarshalJSON decodes the TokenPolicy, keeping unmodelled members in Extra.
+func (p *TokenPolicy) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, p)
+}
+
+// MarshalJSON encodes the SAMLConfig, including its Extra members.
+func (c SAMLConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the SAMLConfig, keeping unmodelled members in Extra.
+func (c *SAMLConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// MarshalJSON encodes the CORSPolicy, including its Extra members.
+func (p CORSPolicy) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(p)
+}
+
+// UnmarshalJSON decodes the CORSPolicy, keeping unmodelled members in Extra.
+func (p *CORSPolicy) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, p)
+}
+
+// MarshalJSON encodes the IdentityZoneLinks, including its Extra members.
+func (l IdentityZoneLinks) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(l)
+}
+
+// UnmarshalJSON decodes the IdentityZoneLinks, keeping unmodelled members in Extra.
+func (l *IdentityZoneLinks) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, l)
+}
+
+// MarshalJSON encodes the Branding, including its Extra members.
+func (b Branding) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(b)
+}
+
+// UnmarshalJSON decodes the Branding, keeping unmodelled members in Extra.
+func (b *Branding) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, b)
+}
+
+// MarshalJSON encodes the IdentityZoneUserConfig, including its Extra members.
+func (c IdentityZoneUserConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the IdentityZoneUserConfig, keeping unmodelled members in Extra.
+func (c *IdentityZoneUserConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// MarshalJSON encodes the IdentityZoneMFAConfig, including its Extra members.
+func (c IdentityZoneMFAConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the IdentityZoneMFAConfig, keeping unmodelled members in Extra.
+func (c *IdentityZoneMFAConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// MarshalJSON encodes the TokenPolicyKey, including its Extra members.
+func (k TokenPolicyKey) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(k)
+}
+
+// UnmarshalJSON decodes the TokenPolicyKey, keeping unmodelled members in Extra.
+func (k *TokenPolicyKey) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, k)
+}
+
+// MarshalJSON encodes the SAMLKey, including its Extra members.
+func (k SAMLKey) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(k)
+}
+
+// UnmarshalJSON decodes the SAMLKey, keeping unmodelled members in Extra.
+func (k *SAMLKey) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, k)
+}
+
+// MarshalJSON encodes the CORSConfiguration, including its Extra members.
+func (c CORSConfiguration) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the CORSConfiguration, keeping unmodelled members in Extra.
+func (c *CORSConfiguration) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// MarshalJSON encodes the LogoutLinks, including its Extra members.
+func (l LogoutLinks) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(l)
+}
+
+// UnmarshalJSON decodes the LogoutLinks, keeping unmodelled members in Extra.
+func (l *LogoutLinks) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, l)
+}
+
+// MarshalJSON encodes the SelfServiceLinks, including its Extra members.
+func (l SelfServiceLinks) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(l)
+}
+
+// UnmarshalJSON decodes the SelfServiceLinks, keeping unmodelled members in Extra.
+func (l *SelfServiceLinks) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, l)
+}
+
+// MarshalJSON encodes the Prompt, including its Extra members.
+func (p Prompt) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(p)
+}
+
+// UnmarshalJSON decodes the Prompt, keeping unmodelled members in Extra.
+func (p *Prompt) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, p)
+}
+
+// MarshalJSON encodes the Banner, including its Extra members.
+func (b Banner) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(b)
+}
+
+// UnmarshalJSON decodes the Banner, keeping unmodelled members in Extra.
+func (b *Banner) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, b)
+}
+
+// MarshalJSON encodes the Consent, including its Extra members.
+func (c Consent) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the Consent, keeping unmodelled members in Extra.
+func (c *Consent) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
+
+// MarshalJSON encodes the IdentityZoneConfig, including its Extra members.
+func (c IdentityZoneConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the IdentityZoneConfig, keeping unmodelled members in Extra.
+func (c *IdentityZoneConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
 }
@@ -0,0 +1,71 @@
+package uaa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TokenIntrospection describes a token, as returned by UAA's introspection
+// endpoint
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#introspect-token.
+type TokenIntrospection struct {
+	// Active reports whether the token is valid. When it is false, UAA
+	// returns no other members.
+	Active    bool     `json:"active"`
+	ClientID  string   `json:"client_id,omitempty"`
+	UserID    string   `json:"user_id,omitempty"`
+	Username  string   `json:"user_name,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Scope     []string `json:"scope,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	ZoneID    string   `json:"zid,omitempty"`
+	GrantType string   `json:"grant_type,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	JTI       string   `json:"jti,omitempty"`
+	// Extra holds the members UAA returned that TokenIntrospection does not
+	// model, such as other claims of the token.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the TokenIntrospection, including its Extra members.
+func (t TokenIntrospection) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(t)
+}
+
+// UnmarshalJSON decodes the TokenIntrospection, keeping unmodelled members
+// in Extra.
+func (t *TokenIntrospection) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, t)
+}
+
+// IntrospectToken asks UAA whether the given token is valid and what it
+// grants. It requires the uaa.resource scope. When the API was created
+// WithOpenIDDiscovery, the discovered introspection endpoint is used.
+func (a *API) IntrospectToken(token string) (*TokenIntrospection, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be blank")
+	}
+	u, err := a.endpointURL(introspectionEndpointPath)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := a.doAndRead(req, true)
+	if err != nil {
+		return nil, err
+	}
+	introspection := &TokenIntrospection{}
+	if err := json.Unmarshal(body, introspection); err != nil {
+		return nil, parseError(err, u.String(), body)
+	}
+	return introspection, nil
+}
@@ -0,0 +1,87 @@
+package uaa_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testIntrospect(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	it("POSTs the token as a form to /introspect", func() {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.Method).To(Equal(http.MethodPost))
+			Expect(req.URL.Path).To(Equal("/introspect"))
+			Expect(req.Header.Get("Content-Type")).To(Equal("application/x-www-form-urlencoded"))
+			Expect(req.ParseForm()).To(Succeed())
+			Expect(req.PostForm.Get("token")).To(Equal("some-token"))
+			w.Header().Set("Content-Type", "application/json")
+			_, err := w.Write([]byte(`{"active":true,"client_id":"app","user_name":"marissa","scope":["openid","uaa.user"],"aud":["app"],"exp":1700000000,"zid":"uaa","origin":"uaa"}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		introspection, err := a.IntrospectToken("some-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(introspection.Active).To(BeTrue())
+		Expect(introspection.ClientID).To(Equal("app"))
+		Expect(introspection.Username).To(Equal("marissa"))
+		Expect(introspection.Scope).To(Equal([]string{"openid", "uaa.user"}))
+		Expect(introspection.ExpiresAt).To(Equal(int64(1700000000)))
+		Expect(introspection.Extra).To(HaveKeyWithValue("origin", json.RawMessage(`"uaa"`)))
+		Expect(called).To(Equal(1))
+	})
+
+	it("reports an inactive token", func() {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, err := w.Write([]byte(`{"active":false}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		introspection, err := a.IntrospectToken("expired-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(introspection.Active).To(BeFalse())
+	})
+
+	it("requires a token", func() {
+		_, err := a.IntrospectToken("")
+		Expect(err).To(MatchError("token cannot be blank"))
+		Expect(called).To(Equal(0))
+	})
+
+	it("returns an error when UAA rejects the request", func() {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		_, err := a.IntrospectToken("some-token")
+		Expect(err).To(HaveOccurred())
+	})
+}
@@ -1,21 +1,154 @@
 package uaa
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sync"
 )
 
+// OpenIDConfigurationEndpoint is the path to the OpenID Connect discovery
+// document.
+const OpenIDConfigurationEndpoint string = "/.well-known/openid-configuration"
+
+// The paths of the endpoints OpenID discovery can locate.
+const (
+	tokenEndpointPath         = "/oauth/token"
+	userInfoEndpointPath      = "/userinfo"
+	jwksEndpointPath          = "/token_keys"
+	introspectionEndpointPath = "/introspect"
+)
+
+// OpenIDConfig is the OpenID Connect discovery document, which describes
+// UAA's endpoints and the features they support
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata.
 type OpenIDConfig struct {
-	Issuer string `json:"issuer"`
+	Issuer                                     string   `json:"issuer"`
+	AuthorizationEndpoint                      string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                              string   `json:"token_endpoint,omitempty"`
+	UserInfoEndpoint                           string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                                    string   `json:"jwks_uri,omitempty"`
+	IntrospectionEndpoint                      string   `json:"introspection_endpoint,omitempty"`
+	EndSessionEndpoint                         string   `json:"end_session_endpoint,omitempty"`
+	TokenEndpointAuthMethodsSupported          []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	TokenEndpointAuthSigningAlgValuesSupported []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	GrantTypesSupported                        []string `json:"grant_types_supported,omitempty"`
+	ResponseTypesSupported                     []string `json:"response_types_supported,omitempty"`
+	ScopesSupported                            []string `json:"scopes_supported,omitempty"`
+	SubjectTypesSupported                      []string `json:"subject_types_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported           []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	IDTokenEncryptionAlgValuesSupported        []string `json:"id_token_encryption_alg_values_supported,omitempty"`
+	ClaimTypesSupported                        []string `json:"claim_types_supported,omitempty"`
+	ClaimsSupported                            []string `json:"claims_supported,omitempty"`
+	ClaimsParameterSupported                   bool     `json:"claims_parameter_supported,omitempty"`
+	CodeChallengeMethodsSupported              []string `json:"code_challenge_methods_supported,omitempty"`
+	ServiceDocumentation                       string   `json:"service_documentation,omitempty"`
+	UILocalesSupported                         []string `json:"ui_locales_supported,omitempty"`
+	// Extra holds the members of the document that OpenIDConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
-// Issuer retrieves an issuer name from openid configuration
-func (a *API) Issuer() (string, error) {
-	url := urlWithPath(*a.TargetURL, "/.well-known/openid-configuration")
+// MarshalJSON encodes the OpenIDConfig, including its Extra members.
+func (c OpenIDConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the OpenIDConfig, keeping unmodelled members in
+// Extra.
+func (c *OpenIDConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
+}
 
+// openIDConfigCache holds the discovery document of an API's target once it
+// has been retrieved.
+type openIDConfigCache struct {
+	mu     sync.Mutex
+	config *OpenIDConfig
+}
+
+// OpenIDConfiguration retrieves the OpenID Connect discovery document. The
+// document is retrieved once and then cached for the life of the API; each
+// call returns a separate copy of it.
+func (a *API) OpenIDConfiguration() (*OpenIDConfig, error) {
+	cache := a.openIDConfigCache
+	if cache != nil {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		if cache.config != nil {
+			return cache.config.copy()
+		}
+	}
+
+	u := urlWithPath(*a.TargetURL, OpenIDConfigurationEndpoint)
 	config := &OpenIDConfig{}
-	err := a.doJSON(http.MethodGet, &url, nil, config, false)
+	err := a.doJSON(http.MethodGet, &u, nil, config, false)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cached, err := config.copy()
+		if err != nil {
+			return nil, err
+		}
+		cache.config = cached
+	}
+	return config, nil
+}
+
+// copy returns a deep copy of the config, which shares no slices or maps
+// with it.
+func (c *OpenIDConfig) copy() (*OpenIDConfig, error) {
+	j, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	copied := &OpenIDConfig{}
+	if err := json.Unmarshal(j, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// Issuer retrieves an issuer name from openid configuration
+func (a *API) Issuer() (string, error) {
+	config, err := a.OpenIDConfiguration()
 	if err != nil {
 		return "", err
 	}
 	return config.Issuer, nil
 }
+
+// endpointURL returns the URL of the endpoint at the given path on the
+// target. When the API was created WithOpenIDDiscovery, it returns the URL
+// the discovery document gives for the endpoint instead, if there is one.
+func (a *API) endpointURL(path string) (url.URL, error) {
+	u := urlWithPath(*a.TargetURL, path)
+	if !a.openIDDiscovery {
+		return u, nil
+	}
+	config, err := a.OpenIDConfiguration()
+	if err != nil {
+		return url.URL{}, fmt.Errorf("discovering the %v endpoint: %v", path, err)
+	}
+	var discovered string
+	switch path {
+	case tokenEndpointPath:
+		discovered = config.TokenEndpoint
+	case userInfoEndpointPath:
+		discovered = config.UserInfoEndpoint
+	case jwksEndpointPath:
+		discovered = config.JWKSURI
+	case introspectionEndpointPath:
+		discovered = config.IntrospectionEndpoint
+	}
+	if discovered == "" {
+		return u, nil
+	}
+	d, err := url.Parse(discovered)
+	if err != nil || !d.IsAbs() {
+		return url.URL{}, fmt.Errorf("the OpenID configuration has an invalid URL %q for the %v endpoint", discovered, path)
+	}
+	return *d, nil
+}
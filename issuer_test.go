@@ -1,12 +1,15 @@
 package uaa_test
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/cloudfoundry-community/go-uaa"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
+	"golang.org/x/oauth2"
 )
 
 var _ = Describe("Issuer", func() {
@@ -56,3 +59,206 @@ var _ = Describe("Issuer", func() {
 	})
 
 })
+
+const openIDConfigurationResponse = `{
+	"issuer": "http://localhost:8080/uaa/oauth/token",
+	"authorization_endpoint": "http://localhost:8080/uaa/oauth/authorize",
+	"token_endpoint": "%[1]s/discovered/oauth/token",
+	"token_endpoint_auth_methods_supported": ["client_secret_basic", "client_secret_post"],
+	"token_endpoint_auth_signing_alg_values_supported": ["RS256", "HS256"],
+	"userinfo_endpoint": "%[1]s/discovered/userinfo",
+	"jwks_uri": "%[1]s/discovered/token_keys",
+	"introspection_endpoint": "%[1]s/discovered/introspect",
+	"end_session_endpoint": "http://localhost:8080/uaa/logout.do",
+	"scopes_supported": ["openid", "profile", "email", "phone", "roles", "user_attributes"],
+	"response_types_supported": ["code", "code id_token", "id_token", "token id_token"],
+	"subject_types_supported": ["public"],
+	"id_token_signing_alg_values_supported": ["RS256", "HS256"],
+	"id_token_encryption_alg_values_supported": ["none"],
+	"claim_types_supported": ["normal"],
+	"claims_supported": ["sub", "user_name", "origin", "iss", "auth_time", "amr", "acr", "client_id", "aud", "zid", "grant_type", "user_id", "azp", "scope", "exp", "iat", "jti", "rev_sig", "cid", "given_name", "family_name", "phone_number", "email"],
+	"claims_parameter_supported": false,
+	"service_documentation": "http://docs.cloudfoundry.org/api/uaa/",
+	"ui_locales_supported": ["en-US"],
+	"code_challenge_methods_supported": ["S256", "plain"],
+	"grant_types_supported": ["authorization_code", "client_credentials", "password", "refresh_token"],
+	"backchannel_logout_supported": false
+}`
+
+var _ = Describe("OpenIDConfiguration", func() {
+	var (
+		server *ghttp.Server
+		api    *uaa.API
+	)
+
+	respondWithConfiguration := func() http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(fmt.Sprintf(openIDConfigurationResponse, server.URL())))
+		}
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the API is created without discovery", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/.well-known/openid-configuration"),
+				respondWithConfiguration(),
+			))
+			var err error
+			api, err = uaa.New(server.URL(), uaa.WithNoAuthentication())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the full discovery document", func() {
+			config, err := api.OpenIDConfiguration()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.TokenEndpoint).To(Equal(server.URL() + "/discovered/oauth/token"))
+			Expect(config.JWKSURI).To(Equal(server.URL() + "/discovered/token_keys"))
+			Expect(config.GrantTypesSupported).To(ContainElement("client_credentials"))
+			Expect(config.ScopesSupported).To(ContainElement("openid"))
+			Expect(config.ClaimsSupported).To(ContainElement("user_name"))
+			Expect(config.IDTokenSigningAlgValuesSupported).To(Equal([]string{"RS256", "HS256"}))
+			Expect(config.CodeChallengeMethodsSupported).To(Equal([]string{"S256", "plain"}))
+			Expect(config.Extra).To(HaveKey("backchannel_logout_supported"))
+		})
+
+		It("retrieves the document only once", func() {
+			_, err := api.OpenIDConfiguration()
+			Expect(err).NotTo(HaveOccurred())
+			issuer, err := api.Issuer()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issuer).To(Equal("http://localhost:8080/uaa/oauth/token"))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("does not let callers modify the cached document", func() {
+			config, err := api.OpenIDConfiguration()
+			Expect(err).NotTo(HaveOccurred())
+			config.GrantTypesSupported[0] = "changed"
+			config.Extra["backchannel_logout_supported"] = []byte("true")
+			config, err = api.OpenIDConfiguration()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.GrantTypesSupported[0]).To(Equal("authorization_code"))
+			Expect(config.Extra["backchannel_logout_supported"]).To(MatchJSON("false"))
+		})
+	})
+
+	Context("when the API is used for several zones", func() {
+		It("retrieves and caches a document for each zone", func() {
+			respondWithIssuer := func(issuer string) http.HandlerFunc {
+				return func(w http.ResponseWriter, req *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"issuer":"` + issuer + `"}`))
+				}
+			}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/.well-known/openid-configuration"),
+					ghttp.VerifyHeaderKV("X-Identity-Zone-Id", "zone-1"),
+					respondWithIssuer("http://zone-1.localhost:8080/uaa/oauth/token"),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/.well-known/openid-configuration"),
+					ghttp.VerifyHeaderKV("X-Identity-Zone-Id", "zone-2"),
+					respondWithIssuer("http://zone-2.localhost:8080/uaa/oauth/token"),
+				),
+			)
+			var err error
+			api, err = uaa.New(server.URL(), uaa.WithNoAuthentication())
+			Expect(err).NotTo(HaveOccurred())
+			zone1 := api.ForZone("zone-1")
+			zone2 := api.ForZone("zone-2")
+
+			issuer, err := zone1.Issuer()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issuer).To(Equal("http://zone-1.localhost:8080/uaa/oauth/token"))
+			issuer, err = zone2.Issuer()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issuer).To(Equal("http://zone-2.localhost:8080/uaa/oauth/token"))
+			issuer, err = zone1.Issuer()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issuer).To(Equal("http://zone-1.localhost:8080/uaa/oauth/token"))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Context("when the API is created WithOpenIDDiscovery", func() {
+		It("uses the discovered token, userinfo, JWKS and introspection endpoints", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/.well-known/openid-configuration"),
+					respondWithConfiguration(),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/discovered/oauth/token"),
+					ghttp.VerifyFormKV("grant_type", "client_credentials"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, &oauth2.Token{
+						AccessToken: "test-access-token",
+						TokenType:   "bearer",
+						Expiry:      time.Now().Add(time.Minute),
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/discovered/userinfo", "scheme=openid"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer test-access-token"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, &uaa.UserInfo{UserID: "user-1"}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/discovered/token_keys"),
+					ghttp.RespondWith(http.StatusOK, `{"keys":[{"kid":"key-1"}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/discovered/introspect"),
+					ghttp.VerifyFormKV("token", "some-token"),
+					ghttp.RespondWith(http.StatusOK, `{"active":true,"client_id":"app"}`),
+				),
+			)
+
+			var err error
+			api, err = uaa.New(server.URL(), uaa.WithClientCredentials("client-id", "client-secret", uaa.JSONWebToken), uaa.WithOpenIDDiscovery())
+			Expect(err).NotTo(HaveOccurred())
+			me, err := api.GetMe()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(me.UserID).To(Equal("user-1"))
+			keys, err := api.TokenKeys()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(1))
+			introspection, err := api.IntrospectToken("some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(introspection.ClientID).To(Equal("app"))
+			Expect(server.ReceivedRequests()).To(HaveLen(5))
+		})
+
+		It("applies the default timeout to the discovery request", func() {
+			client := &http.Client{Transport: http.DefaultTransport}
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/.well-known/openid-configuration"),
+				func(http.ResponseWriter, *http.Request) {
+					Expect(client.Timeout).To(Equal(120 * time.Second))
+				},
+				respondWithConfiguration(),
+			))
+			_, err := uaa.New(server.URL(), uaa.WithClientCredentials("client-id", "client-secret", uaa.JSONWebToken), uaa.WithClient(client), uaa.WithOpenIDDiscovery())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("fails to create the API when discovery fails", func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/.well-known/openid-configuration"),
+				ghttp.RespondWith(http.StatusNotFound, nil),
+			))
+			api, err := uaa.New(server.URL(), uaa.WithClientCredentials("client-id", "client-secret", uaa.JSONWebToken), uaa.WithOpenIDDiscovery())
+			Expect(err).To(HaveOccurred())
+			Expect(api).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package uaa
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// jsonFieldNamesCache caches the JSON member names of struct types.
+var jsonFieldNamesCache sync.Map
+
+// jsonFieldNames returns the JSON member names that encoding/json maps to
+// fields of the given struct type, including promoted fields of embedded
+// structs.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := jsonFieldNamesCache.Load(t); ok {
+		return names.(map[string]bool)
+	}
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for embedded := range jsonFieldNames(ft) {
+					names[embedded] = true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	jsonFieldNamesCache.Store(t, names)
+	return names
+}
+
+// The types that model UAA resources keep the members UAA returns that they
+// do not model in an Extra field, and encode them again, so that a resource
+// read from a newer UAA can be updated without erasing what go-uaa does not
+// know about. Each such type has MarshalJSON and UnmarshalJSON methods that
+// call marshalWithExtra and unmarshalWithExtra. Members of a nested value are
+// preserved only when the nested type has an Extra field of its own.
+
+// marshalWithExtra encodes v, a struct with an Extra field, adding the
+// members of Extra that its fields do not already encode.
+func marshalWithExtra[T any](v T) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	fields := value.Convert(withoutMethods(value.Type())).Interface()
+	return encodeWithExtra(fields, extraOf(value).Interface().(map[string]json.RawMessage))
+}
+
+// unmarshalWithExtra decodes data into v, a struct with an Extra field,
+// keeping the members that v has no field for in Extra.
+func unmarshalWithExtra[T any](data []byte, v *T) error {
+	value := reflect.ValueOf(v).Elem()
+	fields := reflect.New(withoutMethods(value.Type()))
+	extra, err := decodeWithExtra(data, fields.Interface())
+	if err != nil {
+		return err
+	}
+	value.Set(fields.Elem().Convert(value.Type()))
+	extraOf(value).Set(reflect.ValueOf(extra))
+	return nil
+}
+
+func extraOf(value reflect.Value) reflect.Value {
+	extra := value.FieldByName("Extra")
+	if !extra.IsValid() {
+		panic(fmt.Sprintf("%v has no Extra field", value.Type()))
+	}
+	return extra
+}
+
+// methodlessTypes caches the types returned by withoutMethods.
+var methodlessTypes sync.Map
+
+// withoutMethods returns an unnamed struct type with the same fields as the
+// struct type t, to which a t can be converted, so that encoding/json
+// encodes and decodes the fields rather than calling t's JSON methods.
+func withoutMethods(t reflect.Type) reflect.Type {
+	if m, ok := methodlessTypes.Load(t); ok {
+		return m.(reflect.Type)
+	}
+	fields := make([]reflect.StructField, t.NumField())
+	for i := range fields {
+		fields[i] = t.Field(i)
+	}
+	m := reflect.StructOf(fields)
+	methodlessTypes.Store(t, m)
+	return m
+}
+
+// decodeWithExtra decodes data into fields, which must point to a struct
+// type without its own UnmarshalJSON method, and returns the members of data
+// that fields has no field for. It returns a nil map when there are none.
+func decodeWithExtra(data []byte, fields interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, fields); err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	known := jsonFieldNames(reflect.TypeOf(fields).Elem())
+	var extra map[string]json.RawMessage
+	for name, value := range members {
+		if known[name] || knownCaseInsensitive(known, name) {
+			continue
+		}
+		if extra == nil {
+			extra = map[string]json.RawMessage{}
+		}
+		extra[name] = value
+	}
+	return extra, nil
+}
+
+// knownCaseInsensitive reports whether name matches a known member name
+// case-insensitively, as encoding/json does when decoding.
+func knownCaseInsensitive(known map[string]bool, name string) bool {
+	for k := range known {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeWithExtra encodes fields, which must be a struct type without its
+// own MarshalJSON method, adding the members of extra that fields does not
+// already encode.
+func encodeWithExtra(fields interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	j, err := json.Marshal(fields)
+	if err != nil || len(extra) == 0 {
+		return j, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(j, &members); err != nil {
+		return nil, err
+	}
+	known := jsonFieldNames(reflect.TypeOf(fields))
+	for name, value := range extra {
+		if _, ok := members[name]; ok || known[name] {
+			continue
+		}
+		members[name] = value
+	}
+	return json.Marshal(members)
+}
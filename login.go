@@ -0,0 +1,87 @@
+package uaa
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Login server endpoints.
+const (
+	AutologinEndpoint string = "/autologin"
+	PasscodeEndpoint  string = "/passcode"
+)
+
+// AutologinCode is a one-time code that logs a user in to UAA when it is
+// presented at AutologinURL.
+type AutologinCode struct {
+	Code string `json:"code"`
+	// Path is the path UAA suggests redirecting to with the code.
+	Path string `json:"path,omitempty"`
+}
+
+// CreateAutologinCode authenticates the given user and returns a code that
+// starts a browser session for them at AutologinURL. The request is
+// authenticated with the client ID and secret the API was configured with,
+// and the client must have the authorization_code grant type.
+func (a *API) CreateAutologinCode(username string, password string) (*AutologinCode, error) {
+	if a.clientID == "" {
+		return nil, errors.New("an autologin code can only be created by an API configured with a client ID and secret")
+	}
+	if username == "" {
+		return nil, errors.New("username cannot be blank")
+	}
+	if password == "" {
+		return nil, errors.New("password cannot be blank")
+	}
+	j, err := json.Marshal(map[string]string{"username": username, "password": password})
+	if err != nil {
+		return nil, err
+	}
+	u := urlWithPath(*a.TargetURL, AutologinEndpoint)
+	credentials := base64.StdEncoding.EncodeToString([]byte(a.clientID + ":" + a.clientSecret))
+	headers := map[string]string{"Authorization": "Basic " + credentials}
+	code := &AutologinCode{}
+	err = a.doJSONWithHeaders(http.MethodPost, &u, headers, bytes.NewBuffer(j), code, false)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// AutologinURL returns the URL to open in a browser to log in with the given
+// autologin code. UAA redirects from it to the client's redirect URI, or to
+// its home page.
+func (a *API) AutologinURL(code string) (*url.URL, error) {
+	if code == "" {
+		return nil, errors.New("code cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, AutologinEndpoint)
+	query := url.Values{}
+	query.Set("code", code)
+	if a.clientID != "" {
+		query.Set("client_id", a.clientID)
+	}
+	u.RawQuery = query.Encode()
+	return &u, nil
+}
+
+// Passcode returns a one-time passcode for the user the API is authenticated
+// as. The passcode can be exchanged for a token with the password grant and
+// a passcode parameter, so that a session can be handed to another program
+// without sharing the user's password.
+func (a *API) Passcode() (string, error) {
+	u := urlWithPath(*a.TargetURL, PasscodeEndpoint)
+	var passcode string
+	err := a.doJSON(http.MethodGet, &u, nil, &passcode, true)
+	if err != nil {
+		return "", err
+	}
+	if passcode == "" {
+		return "", errors.New("UAA did not return a passcode")
+	}
+	return passcode, nil
+}
@@ -0,0 +1,138 @@
+package uaa_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"golang.org/x/oauth2"
+)
+
+func testLogin(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		var err error
+		a, err = uaa.New(s.URL, uaa.WithClientCredentials("cli", "cli-secret", uaa.JSONWebToken))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("CreateAutologinCode()", func() {
+		it("POSTs the user's credentials with the client's basic auth", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.AutologinEndpoint))
+				clientID, secret, ok := req.BasicAuth()
+				Expect(ok).To(BeTrue())
+				Expect(clientID).To(Equal("cli"))
+				Expect(secret).To(Equal("cli-secret"))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				Expect(body).To(MatchJSON(`{"username":"marissa","password":"koala"}`))
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"code":"xYz123","path":"/oauth/authorize"}`))
+			})
+			code, err := a.CreateAutologinCode("marissa", "koala")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+			Expect(*code).To(Equal(uaa.AutologinCode{Code: "xYz123", Path: "/oauth/authorize"}))
+		})
+
+		it("returns an error when the credentials are rejected", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+			code, err := a.CreateAutologinCode("marissa", "wrong")
+			Expect(err).To(HaveOccurred())
+			Expect(code).To(BeNil())
+		})
+
+		it("requires a username and password", func() {
+			_, err := a.CreateAutologinCode("", "koala")
+			Expect(err).To(HaveOccurred())
+			_, err = a.CreateAutologinCode("marissa", "")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("requires the API to have a client ID", func() {
+			api, err := uaa.New(s.URL, uaa.WithNoAuthentication())
+			Expect(err).NotTo(HaveOccurred())
+			_, err = api.CreateAutologinCode("marissa", "koala")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("AutologinURL()", func() {
+		it("builds the autologin URL with the code and client ID", func() {
+			u, err := a.AutologinURL("xYz123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.String()).To(Equal(s.URL + "/autologin?client_id=cli&code=xYz123"))
+		})
+
+		it("requires a code", func() {
+			u, err := a.AutologinURL("")
+			Expect(err).To(HaveOccurred())
+			Expect(u).To(BeNil())
+		})
+	})
+
+	when("Passcode()", func() {
+		it.Before(func() {
+			var err error
+			a, err = uaa.New(s.URL, uaa.WithToken(&oauth2.Token{
+				AccessToken: "user-token",
+				TokenType:   "bearer",
+				Expiry:      time.Now().Add(time.Hour),
+			}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("GETs a passcode for the authenticated user", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(uaa.PasscodeEndpoint))
+				Expect(req.Header.Get("Authorization")).To(Equal("Bearer user-token"))
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`"mjRZw1Wr8m"`))
+			})
+			passcode, err := a.Passcode()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(passcode).To(Equal("mjRZw1Wr8m"))
+		})
+
+		it("returns an error when UAA does not return a passcode", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+			passcode, err := a.Passcode()
+			Expect(err).To(HaveOccurred())
+			Expect(passcode).To(BeEmpty())
+		})
+	})
+}
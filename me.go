@@ -20,11 +20,14 @@ type UserInfo struct {
 
 // GetMe retrieves the UserInfo for the current user.
 func (a *API) GetMe() (*UserInfo, error) {
-	u := urlWithPath(*a.TargetURL, "/userinfo")
+	u, err := a.endpointURL(userInfoEndpointPath)
+	if err != nil {
+		return nil, err
+	}
 	u.RawQuery = "scheme=openid"
 
 	info := &UserInfo{}
-	err := a.doJSON(http.MethodGet, &u, nil, info, true)
+	err = a.doJSON(http.MethodGet, &u, nil, info, true)
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,49 @@
 package uaa
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
 // MFAProvidersEndpoint is the path to the MFA providers resource.
 const MFAProvidersEndpoint string = "/mfa-providers"
 
+// MFA provider types.
+const (
+	MFAProviderTypeGoogleAuthenticator = "google-authenticator"
+)
+
+// DefaultMFAIdentityProviders are the origin keys of the identity providers
+// whose users UAA requires to use MFA when a zone's
+// IdentityZoneMFAConfig.IdentityProviders is empty.
+var DefaultMFAIdentityProviders = []string{"uaa", "ldap"}
+
 // MFAProviderConfig is configuration for an MFA provider
 type MFAProviderConfig struct {
 	Issuer              string `json:"issuer,omitempty"`
 	ProviderDescription string `json:"providerDescription,omitempty"`
+	// Algorithm, Digits and Duration configure the one-time passcodes of a
+	// google-authenticator provider. UAA defaults them to SHA256, 6 digits
+	// and 30 seconds.
+	Algorithm string `json:"algorithm,omitempty"`
+	Digits    int    `json:"digits,omitempty"`
+	Duration  int    `json:"duration,omitempty"`
+	// Extra holds the members UAA returned that MFAProviderConfig does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the MFAProviderConfig, including its Extra members.
+func (c MFAProviderConfig) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(c)
+}
+
+// UnmarshalJSON decodes the MFAProviderConfig, keeping unmodelled members in
+// Extra.
+func (c *MFAProviderConfig) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, c)
 }
 
 // MFAProvider is a UAA MFA provider
@@ -19,9 +56,112 @@ type MFAProvider struct {
 	Type           string            `json:"type"`
 	Created        int               `json:"created,omitempty"`
 	LastModified   int               `json:"last_modified,omitempty"`
+	// Extra holds the members UAA returned that MFAProvider does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Identifier returns the field used to uniquely identify a MFAProvider.
 func (m MFAProvider) Identifier() string {
 	return m.ID
 }
+
+var (
+	mfaProviderNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+[\sa-zA-Z0-9]*$`)
+	mfaProviderAlgorithms  = []string{"SHA1", "SHA256", "SHA512"}
+)
+
+// Validate checks the MFA provider against the rules UAA applies when it is
+// created, and returns all of the problems it finds.
+func (m MFAProvider) Validate() error {
+	var errs []error
+	switch {
+	case m.Name == "":
+		errs = append(errs, errors.New("name must be specified for the MFA provider"))
+	case len(m.Name) > 255:
+		errs = append(errs, errors.New("MFA provider name cannot be longer than 255 characters"))
+	case !mfaProviderNamePattern.MatchString(m.Name):
+		errs = append(errs, fmt.Errorf("MFA provider name %q must be alphanumeric", m.Name))
+	}
+	switch m.Type {
+	case "":
+		errs = append(errs, errors.New("type must be specified for the MFA provider"))
+	case MFAProviderTypeGoogleAuthenticator:
+		if m.Config.Algorithm != "" && !contains(mfaProviderAlgorithms, m.Config.Algorithm) {
+			errs = append(errs, fmt.Errorf("MFA provider algorithm %v must be one of %v", m.Config.Algorithm, mfaProviderAlgorithms))
+		}
+		if m.Config.Digits < 0 {
+			errs = append(errs, errors.New("MFA provider digits must be positive"))
+		}
+		if m.Config.Duration < 0 {
+			errs = append(errs, errors.New("MFA provider duration must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%v is not a valid MFA provider type, expected %v", m.Type, MFAProviderTypeGoogleAuthenticator))
+	}
+	return errors.Join(errs...)
+}
+
+// UserMFAStatus describes how MFA applies to a user. It does not report
+// whether the user has registered a device, because UAA has no API that
+// exposes MFA registrations.
+type UserMFAStatus struct {
+	UserID string
+	// Origin is the origin key of the user's identity provider.
+	Origin string
+	// Enabled reports whether MFA is enabled in the user's zone.
+	Enabled bool
+	// Required reports whether the user must use MFA to log in, which is
+	// when MFA is enabled and applies to the user's identity provider.
+	Required bool
+	// Provider is the zone's MFA provider, or nil when MFA is not enabled.
+	Provider *MFAProvider
+}
+
+// GetUserMFAStatus reports how MFA applies to the user with the given ID,
+// from the MFA config of the user's zone, its MFA provider and the user's
+// origin. A user who must use MFA and has not registered a device is asked
+// to register one at their next login.
+//
+// zoneID is the ID of the user's zone, and may be empty when the API was
+// created for that zone with ForZone or WithZoneID. Reading the zone's MFA
+// config requires the zones.read or zones.<zone id>.read scope.
+func (a *API) GetUserMFAStatus(userID string, zoneID string) (*UserMFAStatus, error) {
+	if userID == "" {
+		return nil, errors.New("userID cannot be blank")
+	}
+	user, err := a.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	zone, err := a.identityZone(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &UserMFAStatus{UserID: user.ID, Origin: user.Origin}
+	config := zone.Config.MFAConfig
+	if config == nil || config.Enabled == nil || !*config.Enabled {
+		return status, nil
+	}
+	status.Enabled = true
+	origins := config.IdentityProviders
+	if len(origins) == 0 {
+		origins = DefaultMFAIdentityProviders
+	}
+	status.Required = contains(origins, user.Origin)
+
+	providers, err := a.ListMFAProviders()
+	if err != nil {
+		return nil, err
+	}
+	for i := range providers {
+		if providers[i].Name == config.ProviderName {
+			status.Provider = &providers[i]
+			break
+		}
+	}
+	if status.Provider == nil {
+		return nil, fmt.Errorf("MFA provider %v configured for zone %v does not exist", config.ProviderName, zone.ID)
+	}
+	return status, nil
+}
@@ -1,6 +1,14 @@
 package uaa_test
 
-import uaa "github.com/cloudfoundry-community/go-uaa"
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
 
 var mfaproviderResponse string = `{
 	"id": "00000000-0000-0000-0000-000000000001",
@@ -64,3 +72,136 @@ var testMFAProviderJSON string = `{
 	"created": 1529690500934,
 	"last_modified": 1529690500934
 }`
+
+func testMFAProvidersExtra(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("validating MFA providers", func() {
+		it("accepts a google-authenticator provider", func() {
+			provider := uaa.MFAProvider{
+				Name:   "google mfa",
+				Type:   uaa.MFAProviderTypeGoogleAuthenticator,
+				Config: uaa.MFAProviderConfig{Algorithm: "SHA256", Digits: 6, Duration: 30},
+			}
+			Expect(provider.Validate()).To(Succeed())
+			Expect(testMFAProviderValue.Validate()).To(Succeed())
+		})
+
+		it("reports every problem", func() {
+			err := uaa.MFAProvider{Name: "google-mfa", Type: "sms"}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("must be alphanumeric")))
+			Expect(err).To(MatchError(ContainSubstring("sms is not a valid MFA provider type")))
+		})
+
+		it("rejects unknown algorithms", func() {
+			provider := uaa.MFAProvider{
+				Name:   "google",
+				Type:   uaa.MFAProviderTypeGoogleAuthenticator,
+				Config: uaa.MFAProviderConfig{Algorithm: "MD5"},
+			}
+			Expect(provider.Validate()).To(MatchError(ContainSubstring("algorithm MD5")))
+		})
+	})
+
+	when("GetUserMFAStatus()", func() {
+		var zoneMFAConfig string
+
+		it.Before(func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch req.URL.Path {
+				case uaa.UsersEndpoint + "/user-1":
+					w.Write([]byte(`{"id":"user-1","userName":"marcus","origin":"ldap"}`))
+				case uaa.IdentityZonesEndpoint + "/uaa":
+					w.Write([]byte(`{"id":"uaa","subdomain":"","name":"uaa","config":{"mfaConfig":` + zoneMFAConfig + `}}`))
+				case uaa.IdentityZonesEndpoint + "/tenant-id":
+					w.Write([]byte(`{"id":"tenant-id","subdomain":"tenant","name":"tenant","config":{"mfaConfig":{"enabled":false}}}`))
+				case uaa.MFAProvidersEndpoint:
+					w.Write([]byte(mfaproviderListResponse))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+		})
+
+		it("reports that MFA is required when it applies to the user's origin", func() {
+			zoneMFAConfig = `{"enabled":true,"providerName":"sampleGoogleMfaProviderUKaW73","identityProviders":["ldap"]}`
+			status, err := a.GetUserMFAStatus("user-1", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Enabled).To(BeTrue())
+			Expect(status.Required).To(BeTrue())
+			Expect(status.Origin).To(Equal("ldap"))
+			Expect(status.Provider.ID).To(Equal("00000000-0000-0000-0000-000000000002"))
+		})
+
+		it("uses UAA's default identity providers when the zone lists none", func() {
+			zoneMFAConfig = `{"enabled":true,"providerName":"sampleGoogleMfaProviderUKaW73"}`
+			status, err := a.GetUserMFAStatus("user-1", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Required).To(BeTrue())
+		})
+
+		it("reports that MFA is not required for other origins", func() {
+			zoneMFAConfig = `{"enabled":true,"providerName":"sampleGoogleMfaProviderUKaW73","identityProviders":["uaa"]}`
+			status, err := a.GetUserMFAStatus("user-1", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Enabled).To(BeTrue())
+			Expect(status.Required).To(BeFalse())
+		})
+
+		it("does not look up providers when MFA is disabled", func() {
+			zoneMFAConfig = `{"enabled":false}`
+			status, err := a.GetUserMFAStatus("user-1", "uaa")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Enabled).To(BeFalse())
+			Expect(status.Provider).To(BeNil())
+			Expect(called).To(Equal(2))
+		})
+
+		it("uses the zone of an API for a zone", func() {
+			zoneMFAConfig = `{"enabled":true,"providerName":"sampleGoogleMfaProviderUKaW73"}`
+			status, err := a.ForZone("tenant-id").GetUserMFAStatus("user-1", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Enabled).To(BeFalse())
+		})
+
+		it("requires a zone ID when the API is not for a zone", func() {
+			_, err := a.GetUserMFAStatus("user-1", "")
+			Expect(err).To(MatchError("zoneID cannot be blank when the API is not for a zone"))
+		})
+
+		it("returns an error when the zone's provider does not exist", func() {
+			zoneMFAConfig = `{"enabled":true,"providerName":"missing"}`
+			_, err := a.GetUserMFAStatus("user-1", "uaa")
+			Expect(err).To(MatchError(ContainSubstring("MFA provider missing")))
+		})
+
+		it("returns an error when the userID is blank", func() {
+			_, err := a.GetUserMFAStatus("", "uaa")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+	})
+}
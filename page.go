@@ -1,8 +1,63 @@
 package uaa
 
+import "sync"
+
 // Page represents a page of information returned from the UAA API.
 type Page struct {
 	StartIndex   int `json:"startIndex"`
 	ItemsPerPage int `json:"itemsPerPage"`
 	TotalResults int `json:"totalResults"`
 }
+
+// ListQuery describes the filter, sorting and page size used when iterating
+// over a paginated resource.
+type ListQuery struct {
+	Filter     string
+	SortBy     string
+	Attributes string // ignored by resources that do not support attributes
+	SortOrder  SortOrder
+	// ItemsPerPage is the number of items fetched per request (default 100).
+	ItemsPerPage int
+}
+
+// listAllConcurrently fetches the first page with fetch and then, once the
+// total number of results is known, fetches the remaining pages using at most
+// concurrency requests at a time. The pages are reassembled in order.
+func listAllConcurrently[T any](concurrency int, fetch func(startIndex int, itemsPerPage int) ([]T, Page, error)) ([]T, error) {
+	first, page, err := fetch(1, 100)
+	if err != nil {
+		return nil, err
+	}
+	if page.ItemsPerPage <= 0 || len(first) == 0 {
+		return first, nil
+	}
+
+	var startIndexes []int
+	for startIndex := page.StartIndex + page.ItemsPerPage; startIndex <= page.TotalResults; startIndex += page.ItemsPerPage {
+		startIndexes = append(startIndexes, startIndex)
+	}
+
+	pages := make([][]T, len(startIndexes))
+	errs := make([]error, len(startIndexes))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, startIndex := range startIndexes {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, startIndex int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			pages[i], _, errs[i] = fetch(startIndex, page.ItemsPerPage)
+		}(i, startIndex)
+	}
+	wg.Wait()
+
+	results := first
+	for i := range pages {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		results = append(results, pages[i]...)
+	}
+	return results, nil
+}
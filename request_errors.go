@@ -1,6 +1,7 @@
 package uaa
 
 import (
+	stderrors "errors"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -10,9 +11,15 @@ import (
 type RequestError struct {
 	Url           string
 	ErrorResponse []byte
+	// StatusCode is the status of UAA's response, or zero when no response
+	// was received.
+	StatusCode int
 }
 
 func (r RequestError) Error() string {
+	if len(r.ErrorResponse) == 0 {
+		return fmt.Sprintf("An error occurred while calling %s", r.Url)
+	}
 	return fmt.Sprintf("An error occurred while calling %s %s", r.Url, string(r.ErrorResponse))
 }
 
@@ -20,17 +27,19 @@ func requestErrorFromOauthError(err error) error {
 	oauthErrorResponse, isRetrieveError := err.(*oauth2.RetrieveError)
 	if isRetrieveError {
 		tokenUrl := oauthErrorResponse.Response.Request.URL.String()
-		return requestErrorWithBody(tokenUrl, oauthErrorResponse.Body)
+		return requestErrorWithBody(tokenUrl, oauthErrorResponse.Response.StatusCode, oauthErrorResponse.Body)
 	}
 	return err
 }
 
-func requestErrorWithBody(url string, body []byte) error {
-	return RequestError{url, body}
+func requestErrorWithBody(url string, statusCode int, body []byte) error {
+	return RequestError{Url: url, ErrorResponse: body, StatusCode: statusCode}
 }
 
-func requestError(url string) error {
-	return errors.Errorf("An error occurred while calling %s", url)
+// isStatus returns true if err is a RequestError with the given status code.
+func isStatus(err error, statusCode int) bool {
+	var requestErr RequestError
+	return stderrors.As(err, &requestErr) && requestErr.StatusCode == statusCode
 }
 
 func parseError(err error, url string, body []byte) error {
@@ -1,6 +1,7 @@
 package uaa
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -17,14 +18,22 @@ import (
 )
 
 func (a *API) doJSON(method string, url *url.URL, body io.Reader, response interface{}, needsAuthentication bool) error {
+	return a.doJSONWithContext(context.Background(), method, url, body, response, needsAuthentication)
+}
+
+func (a *API) doJSONWithContext(ctx context.Context, method string, url *url.URL, body io.Reader, response interface{}, needsAuthentication bool) error {
 	if strings.Contains(url.Path, "/Users/") || strings.Contains(url.Path, "/Groups/") && method == "PUT" {
-		return a.doJSONWithHeaders(method, url, map[string]string{"If-Match": "*"}, body, response, needsAuthentication)
+		return a.doJSONWithHeadersAndContext(ctx, method, url, map[string]string{"If-Match": "*"}, body, response, needsAuthentication)
 	}
-	return a.doJSONWithHeaders(method, url, nil, body, response, needsAuthentication)
+	return a.doJSONWithHeadersAndContext(ctx, method, url, nil, body, response, needsAuthentication)
 }
 
 func (a *API) doJSONWithHeaders(method string, url *url.URL, headers map[string]string, body io.Reader, response interface{}, needsAuthentication bool) error {
-	req, err := http.NewRequest(method, url.String(), body)
+	return a.doJSONWithHeadersAndContext(context.Background(), method, url, headers, body, response, needsAuthentication)
+}
+
+func (a *API) doJSONWithHeadersAndContext(ctx context.Context, method string, url *url.URL, headers map[string]string, body io.Reader, response interface{}, needsAuthentication bool) error {
+	req, err := http.NewRequestWithContext(ctx, method, url.String(), body)
 	if err != nil {
 		return err
 	}
@@ -56,30 +65,26 @@ func (a *API) doAndRead(req *http.Request, needsAuthentication bool) ([]byte, er
 	req.Header.Set("User-Agent", userAgent)
 	switch req.Method {
 	case http.MethodPut, http.MethodPost, http.MethodPatch:
-		req.Header.Add("Content-Type", "application/json")
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
 	}
-	a.ensureTimeout()
-	var (
-		resp *http.Response
-		err  error
-	)
+	client := a.Client
 	if !needsAuthentication && a.baseClient != nil {
-		a.ensureTransport(a.baseClient.Transport)
-		resp, err = a.baseClient.Do(req)
-	} else {
-		if a.Client == nil {
-			return nil, errors.New("doAndRead: the Client cannot be nil")
-		}
-		a.ensureTransport(a.Client.Transport)
-		resp, err = a.Client.Do(req)
+		client = a.baseClient
 	}
-
+	if client == nil {
+		return nil, errors.New("doAndRead: the Client cannot be nil")
+	}
+	req, cancel := withDefaultTimeout(client, req)
+	defer cancel()
+	resp, err := client.Do(req)
 	if err != nil {
 		if a.verbose {
 			fmt.Printf("%v\n\n", err)
 		}
 
-		return nil, requestError(req.URL.String())
+		return nil, requestErrorWithBody(req.URL.String(), 0, nil)
 	}
 
 	bytes, err := ioutil.ReadAll(resp.Body)
@@ -87,25 +92,41 @@ func (a *API) doAndRead(req *http.Request, needsAuthentication bool) ([]byte, er
 		if a.verbose {
 			fmt.Printf("%v\n\n", err)
 		}
-		return nil, requestError(req.URL.String())
+		return nil, requestErrorWithBody(req.URL.String(), 0, nil)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		if len(bytes) > 0 {
-			return nil, requestErrorWithBody(req.URL.String(), bytes)
-		}
-		return nil, requestError(req.URL.String())
+		return nil, requestErrorWithBody(req.URL.String(), resp.StatusCode, bytes)
 	}
 	return bytes, nil
 }
 
+// defaultTimeout bounds the requests made with a client that has no
+// Timeout of its own.
+const defaultTimeout = 120 * time.Second
+
+// withDefaultTimeout returns req bounded by defaultTimeout when client has no
+// Timeout, so that an API built by hand, or whose Client is replaced after
+// New, cannot wait forever. The returned function releases the timer, and
+// must be called once the response body has been read.
+func withDefaultTimeout(client *http.Client, req *http.Request) (*http.Request, context.CancelFunc) {
+	if client.Timeout != 0 {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), defaultTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// ensureTimeout gives the API's clients the default timeout when they have
+// none, so that requests made outside doAndRead, such as token requests, are
+// bounded too.
 func (a *API) ensureTimeout() {
 	if a.Client != nil && a.Client.Timeout == 0 {
-		a.Client.Timeout = time.Second * 120
+		a.Client.Timeout = defaultTimeout
 	}
 
 	if a.baseClient != nil && a.baseClient.Timeout == 0 {
-		a.baseClient.Timeout = time.Second * 120
+		a.baseClient.Timeout = defaultTimeout
 	}
 }
 
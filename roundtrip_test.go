@@ -225,4 +225,52 @@ func testEnsureTransport(t *testing.T, when spec.G, it spec.S) {
 			})
 		})
 	})
+
+	when("the API is built by hand", func() {
+		it("bounds requests made with a client that has no timeout", func() {
+			var deadline time.Time
+			var hasDeadline bool
+			a.Client = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				deadline, hasDeadline = req.Context().Deadline()
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+			})}
+			req, err := http.NewRequest("GET", "https://example.net", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = a.doAndRead(req, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasDeadline).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("~", defaultTimeout, time.Second))
+			Expect(a.Client.Timeout).To(BeZero())
+		})
+
+		it("leaves requests made with a client that has a timeout to the client", func() {
+			var deadline time.Time
+			a.Client = &http.Client{Timeout: time.Second, Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				deadline, _ = req.Context().Deadline()
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+			})}
+			req, err := http.NewRequest("GET", "https://example.net", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = a.doAndRead(req, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Until(deadline)).To(BeNumerically("<=", time.Second))
+		})
+	})
+
+	when("the API is built", func() {
+		it("configures the transport and timeout before any request is made", func() {
+			transport := &http.Transport{}
+			api, err := New("https://example.net", WithNoAuthentication(), WithClient(&http.Client{Transport: transport}), WithSkipSSLValidation(true))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transport.TLSClientConfig).NotTo(BeNil())
+			Expect(transport.TLSClientConfig.InsecureSkipVerify).To(BeTrue())
+			Expect(api.Client.Timeout).To(Equal(120 * time.Second))
+		})
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
 }
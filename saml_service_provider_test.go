@@ -0,0 +1,229 @@
+package uaa_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+var samlserviceproviderResponse string = `{
+	"id": "00000000-0000-0000-0000-000000000001",
+	"name": "cloudfoundry-saml-login",
+	"entityId": "cloudfoundry-saml-login",
+	"active": true,
+	"config": "{\"metaDataLocation\":\"https://sp.example.com/metadata\",\"nameID\":\"urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress\",\"singleSignOnServiceIndex\":0,\"metadataTrustCheck\":false,\"skipSslValidation\":false,\"attributeMappings\":{\"given_name\":\"firstName\"},\"enableIdpInitiatedSso\":false,\"staticCustomAttributes\":{\"organization\":\"Example\"}}",
+	"identityZoneId": "uaa",
+	"version": 0,
+	"created": 1529690500934,
+	"lastModified": 1529690500934
+}`
+
+var samlserviceproviderListResponse string = `[` + samlserviceproviderResponse + `, {
+	"id": "00000000-0000-0000-0000-000000000002",
+	"name": "other-sp",
+	"entityId": "other-sp",
+	"active": false,
+	"config": "{\"metaDataLocation\":\"https://other.example.com/metadata\"}",
+	"identityZoneId": "uaa"
+}]`
+
+var testSAMLServiceProviderValue uaa.SAMLServiceProvider = uaa.SAMLServiceProvider{
+	ID:       "00000000-0000-0000-0000-000000000001",
+	Name:     "cloudfoundry-saml-login",
+	EntityID: "cloudfoundry-saml-login",
+	Active:   true,
+	Config: uaa.SAMLServiceProviderConfig{
+		MetaDataLocation:       "https://sp.example.com/metadata",
+		NameID:                 uaa.NameIDFormatEmailAddress,
+		AttributeMappings:      map[string]interface{}{"given_name": "firstName"},
+		StaticCustomAttributes: map[string]interface{}{"organization": "Example"},
+	},
+	IdentityZoneID: "uaa",
+	Created:        1529690500934,
+	LastModified:   1529690500934,
+}
+
+var testSAMLServiceProviderJSON string = `{
+	"id": "00000000-0000-0000-0000-000000000001",
+	"name": "cloudfoundry-saml-login",
+	"entityId": "cloudfoundry-saml-login",
+	"active": true,
+	"config": "{\"metaDataLocation\":\"https://sp.example.com/metadata\",\"nameID\":\"urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress\",\"attributeMappings\":{\"given_name\":\"firstName\"},\"staticCustomAttributes\":{\"organization\":\"Example\"}}",
+	"identityZoneId": "uaa",
+	"created": 1529690500934,
+	"lastModified": 1529690500934
+}`
+
+func testCertificate(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sp.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func spMetadata(entityID string, cert string, acsLocation string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="` + entityID + `">
+  <md:SPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo><ds:X509Data><ds:X509Certificate>` + cert + `</ds:X509Certificate></ds:X509Data></ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress</md:NameIDFormat>
+    <md:AssertionConsumerService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="` + acsLocation + `" index="0" isDefault="true"/>
+  </md:SPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+func testSAMLServiceProvidersExtra(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+		cert    string
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		cert = testCertificate(t)
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	it("decodes a config that is a JSON string", func() {
+		var sp uaa.SAMLServiceProvider
+		Expect(json.Unmarshal([]byte(samlserviceproviderResponse), &sp)).To(Succeed())
+		Expect(sp.Config.NameID).To(Equal(uaa.NameIDFormatEmailAddress))
+		Expect(sp.Config.AttributeMappings).To(HaveKeyWithValue("given_name", "firstName"))
+		Expect(sp.Config.StaticCustomAttributes).To(HaveKeyWithValue("organization", "Example"))
+	})
+
+	it("encodes again the config members go-uaa does not model", func() {
+		var sp uaa.SAMLServiceProvider
+		Expect(json.Unmarshal([]byte(`{"name":"my-sp","entityId":"https://sp.example.com","active":true,"config":"{\"nameID\":\"urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress\",\"futureConfigField\":true}"}`), &sp)).To(Succeed())
+		Expect(sp.Config.Extra).To(HaveKeyWithValue("futureConfigField", json.RawMessage(`true`)))
+		j, err := json.Marshal(sp)
+		Expect(err).NotTo(HaveOccurred())
+		var encoded struct {
+			Config string `json:"config"`
+		}
+		Expect(json.Unmarshal(j, &encoded)).To(Succeed())
+		Expect(encoded.Config).To(MatchJSON(`{"nameID":"urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress","futureConfigField":true}`))
+	})
+
+	when("ParseSAMLServiceProviderMetadata()", func() {
+		it("summarizes valid metadata", func() {
+			summary, err := uaa.ParseSAMLServiceProviderMetadata(spMetadata("https://sp.example.com", cert, "https://sp.example.com/acs"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary.EntityID).To(Equal("https://sp.example.com"))
+			Expect(summary.NameIDFormats).To(Equal([]string{uaa.NameIDFormatEmailAddress}))
+			Expect(summary.AssertionConsumerServices).To(Equal([]uaa.SAMLEndpoint{{
+				Binding:   "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST",
+				Location:  "https://sp.example.com/acs",
+				Index:     0,
+				IsDefault: true,
+			}}))
+			Expect(summary.Certificates).To(HaveLen(1))
+			Expect(summary.Certificates[0].Use).To(Equal("signing"))
+			Expect(summary.Certificates[0].Certificate.Subject.CommonName).To(Equal("sp.example.com"))
+		})
+
+		it("reports every problem with invalid metadata", func() {
+			_, err := uaa.ParseSAMLServiceProviderMetadata(spMetadata("", "bm90IGEgY2VydA==", "/acs"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("entityID"))
+			Expect(err.Error()).To(ContainSubstring(`AssertionConsumerService location "/acs" must be an absolute URL`))
+			Expect(err.Error()).To(ContainSubstring("invalid signing certificate"))
+		})
+
+		it("rejects documents that are not SAML metadata", func() {
+			_, err := uaa.ParseSAMLServiceProviderMetadata(`<html></html>`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	when("RegisterSAMLServiceProvider()", func() {
+		it("validates the metadata and takes the entity ID from it", func() {
+			metadata := spMetadata("https://sp.example.com", cert, "https://sp.example.com/acs")
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPost))
+				Expect(req.URL.Path).To(Equal(uaa.SAMLServiceProvidersEndpoint))
+				defer req.Body.Close()
+				body, _ := ioutil.ReadAll(req.Body)
+				var sp uaa.SAMLServiceProvider
+				Expect(json.Unmarshal(body, &sp)).To(Succeed())
+				Expect(sp.EntityID).To(Equal("https://sp.example.com"))
+				Expect(sp.Config.MetaDataLocation).To(Equal(metadata))
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write(body)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			created, summary, err := a.RegisterSAMLServiceProvider(uaa.SAMLServiceProvider{
+				Name:   "my-sp",
+				Active: true,
+				Config: uaa.SAMLServiceProviderConfig{MetaDataLocation: metadata, NameID: uaa.NameIDFormatEmailAddress},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.EntityID).To(Equal("https://sp.example.com"))
+			Expect(summary.AssertionConsumerServices).To(HaveLen(1))
+			Expect(called).To(Equal(1))
+		})
+
+		it("does not upload invalid metadata", func() {
+			_, _, err := a.RegisterSAMLServiceProvider(uaa.SAMLServiceProvider{
+				Name:   "my-sp",
+				Config: uaa.SAMLServiceProviderConfig{MetaDataLocation: strings.Replace(spMetadata("https://sp.example.com", cert, "https://sp.example.com/acs"), "https://sp.example.com/acs", "acs", 1)},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("rejects an entity ID that does not match the metadata", func() {
+			_, _, err := a.RegisterSAMLServiceProvider(uaa.SAMLServiceProvider{
+				Name:     "my-sp",
+				EntityID: "https://other.example.com",
+				Config:   uaa.SAMLServiceProviderConfig{MetaDataLocation: spMetadata("https://sp.example.com", cert, "https://sp.example.com/acs")},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not match"))
+			Expect(called).To(Equal(0))
+		})
+	})
+}
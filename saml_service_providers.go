@@ -0,0 +1,249 @@
+package uaa
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SAMLServiceProvidersEndpoint is the path to the SAML service providers
+// resource.
+const SAMLServiceProvidersEndpoint string = "/saml/service-providers"
+
+// SAML NameID formats.
+const (
+	NameIDFormatUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	NameIDFormatEmailAddress = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIDFormatPersistent   = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	NameIDFormatTransient    = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+)
+
+// SAMLServiceProvider is a SAML service provider for which UAA acts as the
+// identity provider
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#service-providers-2.
+type SAMLServiceProvider struct {
+	ID             string                    `json:"id,omitempty"`
+	Name           string                    `json:"name"`
+	EntityID       string                    `json:"entityId"`
+	Active         bool                      `json:"active"`
+	Config         SAMLServiceProviderConfig `json:"config"`
+	IdentityZoneID string                    `json:"identityZoneId,omitempty"`
+	Version        int                       `json:"version,omitempty"`
+	Created        int                       `json:"created,omitempty"`
+	LastModified   int                       `json:"lastModified,omitempty"`
+	// Extra holds the members UAA returned that SAMLServiceProvider does not
+	// model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Identifier returns the field used to uniquely identify a
+// SAMLServiceProvider.
+func (sp SAMLServiceProvider) Identifier() string {
+	return sp.ID
+}
+
+// SAMLServiceProviderConfig is the configuration of a SAML service provider.
+// UAA transmits it as a JSON-encoded string, which MarshalJSON and
+// UnmarshalJSON take care of.
+type SAMLServiceProviderConfig struct {
+	// MetaDataLocation is either the service provider's metadata XML or a
+	// URL from which UAA can fetch it.
+	MetaDataLocation         string `json:"metaDataLocation,omitempty"`
+	NameID                   string `json:"nameID,omitempty"`
+	SingleSignOnServiceIndex int    `json:"singleSignOnServiceIndex,omitempty"`
+	MetadataTrustCheck       bool   `json:"metadataTrustCheck,omitempty"`
+	SkipSSLValidation        bool   `json:"skipSslValidation,omitempty"`
+	EnableIDPInitiatedSSO    bool   `json:"enableIdpInitiatedSso,omitempty"`
+	// AttributeMappings maps UAA user attributes to the names of the
+	// attributes sent to the service provider.
+	AttributeMappings map[string]interface{} `json:"attributeMappings,omitempty"`
+	// StaticCustomAttributes are attributes with fixed values sent to the
+	// service provider in every assertion.
+	StaticCustomAttributes map[string]interface{} `json:"staticCustomAttributes,omitempty"`
+	// Extra holds the members UAA returned that SAMLServiceProviderConfig
+	// does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the config as a JSON string containing the config.
+func (c SAMLServiceProviderConfig) MarshalJSON() ([]byte, error) {
+	j, err := marshalWithExtra(c)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(j))
+}
+
+// UnmarshalJSON decodes the config from either a JSON string containing the
+// config or the config object itself.
+func (c *SAMLServiceProviderConfig) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "" {
+			*c = SAMLServiceProviderConfig{}
+			return nil
+		}
+		data = []byte(s)
+	}
+	return unmarshalWithExtra(data, c)
+}
+
+// HasMetadataXML reports whether MetaDataLocation holds metadata XML, as
+// opposed to a URL.
+func (c SAMLServiceProviderConfig) HasMetadataXML() bool {
+	return strings.HasPrefix(strings.TrimSpace(c.MetaDataLocation), "<")
+}
+
+// SAMLEndpoint is a SAML protocol endpoint declared in metadata.
+type SAMLEndpoint struct {
+	Binding   string
+	Location  string
+	Index     int
+	IsDefault bool
+}
+
+// SAMLCertificate is a certificate declared in metadata, with the use
+// ("signing", "encryption", or empty for both) it is declared for.
+type SAMLCertificate struct {
+	Use         string
+	Certificate *x509.Certificate
+}
+
+// SAMLServiceProviderMetadata summarizes a service provider's metadata.
+type SAMLServiceProviderMetadata struct {
+	EntityID                  string
+	AssertionConsumerServices []SAMLEndpoint
+	NameIDFormats             []string
+	Certificates              []SAMLCertificate
+}
+
+type samlEntityDescriptor struct {
+	XMLName         xml.Name                 `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string                   `xml:"entityID,attr"`
+	SPSSODescriptor []samlSPSSODescriptorXML `xml:"urn:oasis:names:tc:SAML:2.0:metadata SPSSODescriptor"`
+}
+
+type samlSPSSODescriptorXML struct {
+	KeyDescriptors            []samlKeyDescriptorXML `xml:"urn:oasis:names:tc:SAML:2.0:metadata KeyDescriptor"`
+	NameIDFormats             []string               `xml:"urn:oasis:names:tc:SAML:2.0:metadata NameIDFormat"`
+	AssertionConsumerServices []samlEndpointXML      `xml:"urn:oasis:names:tc:SAML:2.0:metadata AssertionConsumerService"`
+}
+
+type samlKeyDescriptorXML struct {
+	Use              string   `xml:"use,attr"`
+	X509Certificates []string `xml:"http://www.w3.org/2000/09/xmldsig# KeyInfo>X509Data>X509Certificate"`
+}
+
+type samlEndpointXML struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// ParseSAMLServiceProviderMetadata validates the given service provider
+// metadata XML and summarizes it. The metadata must have an entityID, an
+// SPSSODescriptor with at least one absolute AssertionConsumerService
+// location, and only well-formed X.509 certificates.
+func ParseSAMLServiceProviderMetadata(metadata string) (*SAMLServiceProviderMetadata, error) {
+	var ed samlEntityDescriptor
+	if err := xml.Unmarshal([]byte(metadata), &ed); err != nil {
+		return nil, fmt.Errorf("invalid SAML service provider metadata: %v", err)
+	}
+
+	var errs []error
+	if ed.EntityID == "" {
+		errs = append(errs, errors.New("metadata must specify an entityID"))
+	}
+	if len(ed.SPSSODescriptor) == 0 {
+		errs = append(errs, errors.New("metadata must contain an SPSSODescriptor"))
+	}
+
+	summary := &SAMLServiceProviderMetadata{EntityID: ed.EntityID}
+	for _, sp := range ed.SPSSODescriptor {
+		summary.NameIDFormats = append(summary.NameIDFormats, sp.NameIDFormats...)
+		for _, acs := range sp.AssertionConsumerServices {
+			if u, err := url.Parse(acs.Location); err != nil || !u.IsAbs() {
+				errs = append(errs, fmt.Errorf("AssertionConsumerService location %q must be an absolute URL", acs.Location))
+				continue
+			}
+			summary.AssertionConsumerServices = append(summary.AssertionConsumerServices, SAMLEndpoint(acs))
+		}
+		for _, kd := range sp.KeyDescriptors {
+			for _, encoded := range kd.X509Certificates {
+				der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+				if err != nil {
+					errs = append(errs, fmt.Errorf("invalid %v certificate: %v", kd.Use, err))
+					continue
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("invalid %v certificate: %v", kd.Use, err))
+					continue
+				}
+				summary.Certificates = append(summary.Certificates, SAMLCertificate{Use: kd.Use, Certificate: cert})
+			}
+		}
+	}
+	if len(ed.SPSSODescriptor) > 0 && len(summary.AssertionConsumerServices) == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("metadata must declare at least one AssertionConsumerService"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Validate checks that the service provider can be registered. When its
+// config holds metadata XML, the metadata is validated, the entityID it
+// declares must match EntityID, and its summary is returned; otherwise the
+// returned summary is nil.
+func (sp SAMLServiceProvider) Validate() (*SAMLServiceProviderMetadata, error) {
+	if sp.Name == "" {
+		return nil, errors.New("name must be specified for the SAML service provider")
+	}
+	if sp.Config.MetaDataLocation == "" {
+		return nil, errors.New("metaDataLocation must be specified for the SAML service provider")
+	}
+	if !sp.Config.HasMetadataXML() {
+		if u, err := url.Parse(sp.Config.MetaDataLocation); err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("metaDataLocation %q must be metadata XML or an absolute URL", sp.Config.MetaDataLocation)
+		}
+		return nil, nil
+	}
+	summary, err := ParseSAMLServiceProviderMetadata(sp.Config.MetaDataLocation)
+	if err != nil {
+		return nil, err
+	}
+	if sp.EntityID != "" && sp.EntityID != summary.EntityID {
+		return nil, fmt.Errorf("entityId %v does not match the metadata entityID %v", sp.EntityID, summary.EntityID)
+	}
+	return summary, nil
+}
+
+// RegisterSAMLServiceProvider validates the given service provider and, if
+// it is valid, creates it. When the provider's EntityID is empty it is taken
+// from the metadata.
+func (a *API) RegisterSAMLServiceProvider(sp SAMLServiceProvider) (*SAMLServiceProvider, *SAMLServiceProviderMetadata, error) {
+	summary, err := sp.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+	if sp.EntityID == "" && summary != nil {
+		sp.EntityID = summary.EntityID
+	}
+	if sp.EntityID == "" {
+		return nil, nil, errors.New("entityId must be specified for the SAML service provider")
+	}
+	created, err := a.CreateSAMLServiceProvider(sp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, summary, nil
+}
@@ -0,0 +1,262 @@
+// Package scim builds and parses the SCIM filter expressions accepted by the
+// List* functions of the go-uaa package
+// (http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#list-with-attribute-filtering).
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter is a SCIM filter expression. Its String method renders the
+// expression, with all values quoted and escaped.
+//
+// The functions that build filters panic if they are given an attribute name
+// that is not a valid SCIM attribute path, or a nil filter to negate. Use
+// ValidateAttribute to check attribute names that come from user input.
+type Filter interface {
+	String() string
+	precedence() int
+}
+
+// Operator is a SCIM attribute operator.
+type Operator string
+
+// Valid Operator values.
+const (
+	OpEq Operator = "eq"
+	OpNe Operator = "ne"
+	OpCo Operator = "co"
+	OpSw Operator = "sw"
+	OpEw Operator = "ew"
+	OpGt Operator = "gt"
+	OpGe Operator = "ge"
+	OpLt Operator = "lt"
+	OpLe Operator = "le"
+	OpPr Operator = "pr"
+)
+
+const (
+	precedenceOr = iota
+	precedenceAnd
+	precedenceUnary
+)
+
+type comparison struct {
+	attribute string
+	operator  Operator
+	value     string
+}
+
+func (c comparison) String() string {
+	if c.operator == OpPr {
+		return fmt.Sprintf("%s pr", c.attribute)
+	}
+	return fmt.Sprintf("%s %s %s", c.attribute, c.operator, c.value)
+}
+
+func (c comparison) precedence() int {
+	return precedenceUnary
+}
+
+type logical struct {
+	operator string
+	filters  []Filter
+	prec     int
+}
+
+func (l logical) String() string {
+	parts := make([]string, 0, len(l.filters))
+	for _, f := range l.filters {
+		parts = append(parts, group(f, l.prec))
+	}
+	return strings.Join(parts, " "+l.operator+" ")
+}
+
+func (l logical) precedence() int {
+	return l.prec
+}
+
+type not struct {
+	filter Filter
+}
+
+func (n not) String() string {
+	return fmt.Sprintf("not (%s)", n.filter)
+}
+
+func (n not) precedence() int {
+	return precedenceUnary
+}
+
+// group parenthesizes f when it binds more loosely than its parent.
+func group(f Filter, parent int) string {
+	if f.precedence() < parent {
+		return "(" + f.String() + ")"
+	}
+	return f.String()
+}
+
+// Eq matches resources whose attribute equals value.
+func Eq(attribute string, value interface{}) Filter {
+	return compare(attribute, OpEq, value)
+}
+
+// Ne matches resources whose attribute does not equal value.
+func Ne(attribute string, value interface{}) Filter {
+	return compare(attribute, OpNe, value)
+}
+
+// Co matches resources whose attribute contains value.
+func Co(attribute string, value string) Filter {
+	return compare(attribute, OpCo, value)
+}
+
+// Sw matches resources whose attribute starts with value.
+func Sw(attribute string, value string) Filter {
+	return compare(attribute, OpSw, value)
+}
+
+// Ew matches resources whose attribute ends with value.
+func Ew(attribute string, value string) Filter {
+	return compare(attribute, OpEw, value)
+}
+
+// Gt matches resources whose attribute is greater than value.
+func Gt(attribute string, value interface{}) Filter {
+	return compare(attribute, OpGt, value)
+}
+
+// Ge matches resources whose attribute is greater than or equal to value.
+func Ge(attribute string, value interface{}) Filter {
+	return compare(attribute, OpGe, value)
+}
+
+// Lt matches resources whose attribute is less than value.
+func Lt(attribute string, value interface{}) Filter {
+	return compare(attribute, OpLt, value)
+}
+
+// Le matches resources whose attribute is less than or equal to value.
+func Le(attribute string, value interface{}) Filter {
+	return compare(attribute, OpLe, value)
+}
+
+// Pr matches resources that have a value for attribute.
+func Pr(attribute string) Filter {
+	mustBeAttribute(attribute)
+	return comparison{attribute: attribute, operator: OpPr}
+}
+
+// And matches resources that match all of the given filters. Nil filters are
+// ignored; And returns nil if no filters remain.
+func And(filters ...Filter) Filter {
+	return combine("and", precedenceAnd, filters)
+}
+
+// Or matches resources that match any of the given filters. Nil filters are
+// ignored; Or returns nil if no filters remain.
+func Or(filters ...Filter) Filter {
+	return combine("or", precedenceOr, filters)
+}
+
+// Not matches resources that do not match the given filter. It panics if
+// filter is nil, because a nil filter matches every resource and its negation
+// cannot be expressed.
+func Not(filter Filter) Filter {
+	if filter == nil {
+		panic("scim: Not requires a non-nil filter")
+	}
+	return not{filter: filter}
+}
+
+// ValidateAttribute returns an error if attribute is not a valid SCIM
+// attribute path, such as userName or meta.lastModified.
+func ValidateAttribute(attribute string) error {
+	if !attributePattern.MatchString(attribute) {
+		return fmt.Errorf("invalid attribute name %q", attribute)
+	}
+	return nil
+}
+
+func mustBeAttribute(attribute string) {
+	if err := ValidateAttribute(attribute); err != nil {
+		panic("scim: " + err.Error())
+	}
+}
+
+func compare(attribute string, operator Operator, value interface{}) Filter {
+	mustBeAttribute(attribute)
+	return comparison{attribute: attribute, operator: operator, value: Value(value)}
+}
+
+func combine(operator string, prec int, filters []Filter) Filter {
+	var nonNil []Filter
+	for _, f := range filters {
+		if f != nil {
+			nonNil = append(nonNil, f)
+		}
+	}
+	switch len(nonNil) {
+	case 0:
+		return nil
+	case 1:
+		return nonNil[0]
+	}
+	return logical{operator: operator, filters: nonNil, prec: prec}
+}
+
+// Value renders a Go value as a SCIM filter value. Strings and times are
+// quoted and escaped; booleans, numbers and nil are rendered as literals. Any
+// other value is rendered with fmt and quoted.
+func Value(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return Quote(v.UTC().Format("2006-01-02T15:04:05.000Z"))
+	case fmt.Stringer:
+		return Quote(v.String())
+	}
+	return Quote(fmt.Sprintf("%v", value))
+}
+
+// Quote returns s as a double-quoted SCIM string, escaping backslashes, double
+// quotes and control characters.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package scim_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry-community/go-uaa/scim"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testFilter(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	when("building filters", func() {
+		it("renders attribute operators", func() {
+			Expect(scim.Eq("userName", "marcus").String()).To(Equal(`userName eq "marcus"`))
+			Expect(scim.Ne("active", false).String()).To(Equal(`active ne false`))
+			Expect(scim.Co("displayName", "cloud").String()).To(Equal(`displayName co "cloud"`))
+			Expect(scim.Sw("userName", "m").String()).To(Equal(`userName sw "m"`))
+			Expect(scim.Ew("userName", ".com").String()).To(Equal(`userName ew ".com"`))
+			Expect(scim.Pr("externalId").String()).To(Equal(`externalId pr`))
+			Expect(scim.Gt("meta.version", 3).String()).To(Equal(`meta.version gt 3`))
+			Expect(scim.Le("meta.lastModified", time.Date(2017, 8, 15, 16, 54, 15, 0, time.UTC)).String()).
+				To(Equal(`meta.lastModified le "2017-08-15T16:54:15.000Z"`))
+		})
+
+		it("escapes quotes and backslashes in values", func() {
+			Expect(scim.Eq("userName", `ma"rc\us`).String()).To(Equal(`userName eq "ma\"rc\\us"`))
+			Expect(scim.Eq("userName", `x" or userName pr or userName eq "y`).String()).
+				To(Equal(`userName eq "x\" or userName pr or userName eq \"y"`))
+		})
+
+		it("combines filters with and, or and not", func() {
+			f := scim.And(
+				scim.Or(scim.Eq("userName", "marcus"), scim.Eq("userName", "drseuss")),
+				scim.Eq("origin", "uaa"),
+				scim.Not(scim.Eq("active", false)),
+			)
+			Expect(f.String()).To(Equal(`(userName eq "marcus" or userName eq "drseuss") and origin eq "uaa" and not (active eq false)`))
+		})
+
+		it("does not parenthesize and inside or", func() {
+			f := scim.Or(scim.And(scim.Pr("a"), scim.Pr("b")), scim.Pr("c"))
+			Expect(f.String()).To(Equal(`a pr and b pr or c pr`))
+		})
+
+		it("ignores nil filters", func() {
+			Expect(scim.And(scim.Eq("userName", "marcus"), nil).String()).To(Equal(`userName eq "marcus"`))
+			Expect(scim.Or()).To(BeNil())
+		})
+
+		it("panics when negating a nil filter", func() {
+			Expect(func() { scim.Not(nil) }).To(PanicWith("scim: Not requires a non-nil filter"))
+			Expect(func() { scim.Not(scim.And()) }).To(Panic())
+		})
+
+		it("panics when an attribute name is not a valid attribute path", func() {
+			for _, attribute := range []string{``, `user name`, `userName"`, `userName eq "x" or id`, `1id`} {
+				Expect(scim.ValidateAttribute(attribute)).To(HaveOccurred(), attribute)
+				Expect(func() { scim.Eq(attribute, "marcus") }).To(Panic(), attribute)
+				Expect(func() { scim.Pr(attribute) }).To(Panic(), attribute)
+			}
+			Expect(scim.ValidateAttribute("meta.lastModified")).To(Succeed())
+			Expect(scim.ValidateAttribute("urn:ietf:params:scim:schemas:core:2.0:User:userName")).To(Succeed())
+		})
+	})
+
+	when("Parse()", func() {
+		it("round-trips built filters", func() {
+			f := scim.And(
+				scim.Or(scim.Eq("userName", `ma"rcus`), scim.Sw("userName", "dr")),
+				scim.Not(scim.Pr("externalId")),
+				scim.Gt("meta.version", 2),
+			)
+			parsed, err := scim.Parse(f.String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.String()).To(Equal(f.String()))
+		})
+
+		it("accepts case-insensitive keywords and operators", func() {
+			parsed, err := scim.Parse(`userName EQ "marcus" AND active Eq TRUE`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.String()).To(Equal(`userName eq "marcus" and active eq true`))
+		})
+
+		it("rejects invalid filters", func() {
+			for _, filter := range []string{
+				``,
+				`userName`,
+				`userName eq`,
+				`userName like "marcus"`,
+				`userName eq marcus`,
+				`userName eq "marcus`,
+				`(userName eq "marcus"`,
+				`userName eq "marcus")`,
+				`userName eq "marcus" and`,
+				`"userName" eq "marcus"`,
+				`not userName pr`,
+			} {
+				Expect(scim.Validate(filter)).To(HaveOccurred(), filter)
+			}
+		})
+	})
+}
@@ -0,0 +1,228 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var attributePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$.:-]*$`)
+
+// Parse parses a SCIM filter expression, such as one supplied by a user, and
+// returns the equivalent Filter. It returns an error describing the first
+// problem found if the expression is not valid.
+func Parse(filter string) (Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("filter cannot be blank")
+	}
+	p := &parser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+	return f, nil
+}
+
+// Validate returns nil if filter is a valid SCIM filter expression, or an
+// error describing the first problem found.
+func Validate(filter string) error {
+	_, err := Parse(filter)
+	return err
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: i})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(s) && !strings.ContainsRune(" \t\n\r()\"", rune(s[end])); end++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: s[i:end], pos: i})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) done() bool {
+	return p.next >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	return !p.done() && p.peek().kind == tokenWord && strings.EqualFold(p.peek().text, keyword)
+}
+
+func (p *parser) expect(kind tokenKind, description string) (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("expected %s at end of filter", description)
+	}
+	t := p.peek()
+	if t.kind != kind {
+		return token{}, fmt.Errorf("expected %s at position %d, found %q", description, t.pos, t.text)
+	}
+	p.next++
+	return t, nil
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	filters := []Filter{f}
+	for p.peekKeyword("or") {
+		p.next++
+		f, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return Or(filters...), nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	f, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	filters := []Filter{f}
+	for p.peekKeyword("and") {
+		p.next++
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return And(filters...), nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.next++
+		f, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return Not(f), nil
+	}
+	if !p.done() && p.peek().kind == tokenOpen {
+		return p.parseGroup()
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseGroup() (Filter, error) {
+	if _, err := p.expect(tokenOpen, `"("`); err != nil {
+		return nil, err
+	}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenClose, `")"`); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (p *parser) parseComparison() (Filter, error) {
+	attribute, err := p.expect(tokenWord, "an attribute name")
+	if err != nil {
+		return nil, err
+	}
+	if !attributePattern.MatchString(attribute.text) {
+		return nil, fmt.Errorf("invalid attribute name %q at position %d", attribute.text, attribute.pos)
+	}
+	op, err := p.expect(tokenWord, "an operator")
+	if err != nil {
+		return nil, err
+	}
+	operator := Operator(strings.ToLower(op.text))
+	switch operator {
+	case OpPr:
+		return Pr(attribute.text), nil
+	case OpEq, OpNe, OpCo, OpSw, OpEw, OpGt, OpGe, OpLt, OpLe:
+	default:
+		return nil, fmt.Errorf("unknown operator %q at position %d", op.text, op.pos)
+	}
+
+	if p.done() {
+		return nil, fmt.Errorf("expected a value at end of filter")
+	}
+	value := p.peek()
+	p.next++
+	switch value.kind {
+	case tokenString:
+		return comparison{attribute: attribute.text, operator: operator, value: Quote(value.text)}, nil
+	case tokenWord:
+		literal := strings.ToLower(value.text)
+		if literal == "true" || literal == "false" || literal == "null" {
+			return comparison{attribute: attribute.text, operator: operator, value: literal}, nil
+		}
+		if _, err := strconv.ParseFloat(value.text, 64); err == nil {
+			return comparison{attribute: attribute.text, operator: operator, value: value.text}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid value %q at position %d", value.text, value.pos)
+}
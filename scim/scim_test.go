@@ -0,0 +1,12 @@
+package scim_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestSCIM(t *testing.T) {
+	spec.Run(t, "scim", testFilter, spec.Report(report.Terminal{}))
+}
@@ -11,9 +11,12 @@ type Keys struct {
 
 // TokenKeys gets the JSON Web Token signing keys for the UAA server.
 func (a *API) TokenKeys() ([]JWK, error) {
-	url := urlWithPath(*a.TargetURL, "/token_keys")
+	url, err := a.endpointURL(jwksEndpointPath)
+	if err != nil {
+		return nil, err
+	}
 	keys := &Keys{}
-	err := a.doJSON(http.MethodGet, &url, nil, keys, false)
+	err = a.doJSON(http.MethodGet, &url, nil, keys, false)
 	if err != nil {
 		key, e := a.TokenKey()
 		if e != nil {
@@ -14,6 +14,7 @@ func init() {
 	suite("ensureTransport", testEnsureTransport)
 	suite("contains", testContains)
 	suite("URLWithPath", testURLWithPath)
+	suite("URLWithEscapedSegments", testURLWithEscapedSegments)
 	suite("api", testAPI)
 	suite("uaaTransport", testUaaTransport)
 }
@@ -13,20 +13,39 @@ func init() {
 	suite = spec.New("uaa", spec.Report(report.Terminal{}))
 	suite("new", testNew)
 	suite("clientExtra", testClientExtra)
+	suite("clientMetadata", testClientMetadata)
+	suite("clientTransactions", testClientTransactions)
+	suite("clientSecrets", testClientSecrets)
+	suite("cloneIdentityZone", testCloneIdentityZone)
+	suite("codes", testCodes)
 	suite("curl", testCurl)
 	suite("groupsExtra", testGroupsExtra)
+	suite("groupGraph", testGroupGraph)
+	suite("groupSync", testGroupSync)
+	suite("groupMappingSync", testGroupMappingSync)
 	suite("isHealthy", testIsHealthy)
+	suite("identityProvidersExtra", testIdentityProvidersExtra)
+	suite("identityZonesExtra", testIdentityZonesExtra)
 	suite("info", testInfo)
+	suite("introspect", testIntrospect)
+	suite("login", testLogin)
 	suite("me", testMe)
+	suite("mfaProvidersExtra", testMFAProvidersExtra)
+	suite("samlServiceProvidersExtra", testSAMLServiceProvidersExtra)
 	suite("tokenKey", testTokenKey)
 	suite("tokenKeys", testTokenKeys)
 	suite("buildSubdomainURL", testBuildSubdomainURL)
 	suite("users", testUsers)
+	suite("userIDs", testUserIDs)
+	suite("zoneClients", testZoneClients)
+	suite("zoneKeyRotation", testZoneKeyRotation)
 
 	// Generated
 	suite("client", testClient)
 	suite("group", testGroup)
+	suite("identityProvider", testIdentityProvider)
 	suite("identityZone", testIdentityZone)
+	suite("samlServiceProvider", testSAMLServiceProvider)
 	suite("mfaProvider", testMFAProvider)
 	suite("user", testUser)
 }
@@ -37,3 +37,17 @@ func urlWithPath(u url.URL, p string) url.URL {
 	u.Path = path.Join(u.Path, p)
 	return u
 }
+
+// urlWithEscapedSegments copies the URL and sets the path on the copy to p
+// followed by each of the segments. Each segment is escaped, so that slashes
+// and commas (as found in LDAP DNs) are not mistaken for path structure.
+func urlWithEscapedSegments(u url.URL, p string, segments ...string) url.URL {
+	u = urlWithPath(u, p)
+	rawPath := u.EscapedPath()
+	for _, segment := range segments {
+		u.Path = u.Path + "/" + segment
+		rawPath = rawPath + "/" + strings.ReplaceAll(url.PathEscape(segment), ",", "%2C")
+	}
+	u.RawPath = rawPath
+	return u
+}
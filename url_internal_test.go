@@ -24,3 +24,18 @@ func testURLWithPath(t *testing.T, when spec.G, it spec.S) {
 		Expect(withPath.String()).To(Equal("http://example.com/uaa/path"))
 	})
 }
+
+func testURLWithEscapedSegments(t *testing.T, when spec.G, it spec.S) {
+	it.Before(func() {
+		RegisterTestingT(t)
+	})
+
+	it("escapes slashes and commas in each segment", func() {
+		u, err := url.Parse("http://example.com/uaa")
+		Expect(err).NotTo(HaveOccurred())
+
+		withSegments := urlWithEscapedSegments(*u, "/Groups/External", "externalGroup", "cn=a/b,ou=groups", "origin", "ldap")
+		Expect(withSegments.String()).To(Equal("http://example.com/uaa/Groups/External/externalGroup/cn=a%2Fb%2Cou=groups/origin/ldap"))
+		Expect(withSegments.Path).To(Equal("/uaa/Groups/External/externalGroup/cn=a/b,ou=groups/origin/ldap"))
+	})
+}
@@ -0,0 +1,155 @@
+package uaa
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-community/go-uaa/scim"
+)
+
+// UserIDsEndpoint is the path to the user ID lookup resource.
+const UserIDsEndpoint string = "/ids/Users"
+
+// userIDLookupChunkSize is the number of values combined into a single
+// OR-filter by ResolveUsernames and ResolveUserIDs.
+const userIDLookupChunkSize = 50
+
+// UserIDEntry maps a user ID to a username and origin.
+type UserIDEntry struct {
+	ID       string `json:"id,omitempty"`
+	Username string `json:"userName,omitempty"`
+	Origin   string `json:"origin,omitempty"`
+}
+
+// paginatedUserIDList is the response from the API for a single page of user
+// ID entries.
+type paginatedUserIDList struct {
+	Page
+	Resources []UserIDEntry `json:"resources"`
+	Schemas   []string      `json:"schemas"`
+}
+
+// LookupUserIDs retrieves every user ID entry that matches the given filter.
+// Unlike ListAllUsers, this does not require the scim.read scope
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#lookup-user-ids-usernames.
+func (a *API) LookupUserIDs(filter string) ([]UserIDEntry, error) {
+	page := Page{
+		StartIndex:   1,
+		ItemsPerPage: 100,
+	}
+	var results []UserIDEntry
+
+	for {
+		u := urlWithPath(*a.TargetURL, UserIDsEndpoint)
+		query := url.Values{}
+		if filter != "" {
+			query.Set("filter", filter)
+		}
+		query.Set("startIndex", strconv.Itoa(page.StartIndex))
+		query.Set("count", strconv.Itoa(page.ItemsPerPage))
+		u.RawQuery = query.Encode()
+
+		entries := &paginatedUserIDList{}
+		err := a.doJSON(http.MethodGet, &u, nil, entries, true)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, entries.Resources...)
+
+		if entries.ItemsPerPage > 0 {
+			page.ItemsPerPage = entries.ItemsPerPage
+		}
+		if (page.StartIndex+page.ItemsPerPage) > entries.TotalResults || len(entries.Resources) == 0 {
+			break
+		}
+		page.StartIndex = page.StartIndex + page.ItemsPerPage
+	}
+	return results, nil
+}
+
+// ResolveUsernames returns a map of user ID to username for the given user IDs.
+// IDs that do not match a user are omitted from the map.
+func (a *API) ResolveUsernames(ids []string) (map[string]string, error) {
+	result := make(map[string]string, len(ids))
+	err := a.lookupUserIDsInChunks("id", ids, "", func(entry UserIDEntry) {
+		result[entry.ID] = entry.Username
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResolveUserIDs returns a map of username to user ID for the given usernames.
+// If origin is not blank, only users in that origin are matched. Usernames
+// that do not match a user are omitted from the map. When origin is blank and
+// a username matches users in more than one origin, ResolveUserIDs returns an
+// error naming the ambiguous usernames rather than picking one of the users.
+func (a *API) ResolveUserIDs(usernames []string, origin string) (map[string]string, error) {
+	result := make(map[string]string, len(usernames))
+	origins := make(map[string][]string, len(usernames))
+	err := a.lookupUserIDsInChunks("userName", usernames, origin, func(entry UserIDEntry) {
+		result[entry.Username] = entry.ID
+		origins[entry.Username] = append(origins[entry.Username], entry.Origin)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ambiguous []string
+	for username, matched := range origins {
+		if len(matched) > 1 {
+			sort.Strings(matched)
+			ambiguous = append(ambiguous, fmt.Sprintf("%v (origins %v)", username, strings.Join(matched, ", ")))
+		}
+	}
+	if len(ambiguous) > 0 {
+		sort.Strings(ambiguous)
+		return nil, fmt.Errorf("usernames match users in more than one origin, specify an origin: %v", strings.Join(ambiguous, "; "))
+	}
+	return result, nil
+}
+
+func (a *API) lookupUserIDsInChunks(attribute string, values []string, origin string, collect func(UserIDEntry)) error {
+	values = uniqueNonBlank(values)
+	for start := 0; start < len(values); start += userIDLookupChunkSize {
+		end := start + userIDLookupChunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		clauses := make([]scim.Filter, 0, end-start)
+		for _, value := range values[start:end] {
+			clauses = append(clauses, scim.Eq(attribute, value))
+		}
+		filter := scim.Or(clauses...)
+		if origin != "" {
+			filter = scim.And(filter, scim.Eq("origin", origin))
+		}
+
+		entries, err := a.LookupUserIDs(filter.String())
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			collect(entry)
+		}
+	}
+	return nil
+}
+
+func uniqueNonBlank(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package uaa_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testUserIDs(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s       *httptest.Server
+		handler http.Handler
+		called  int
+		a       *uaa.API
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		called = 0
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = called + 1
+			Expect(handler).NotTo(BeNil())
+			handler.ServeHTTP(w, req)
+		}))
+		a, _ = uaa.New(s.URL, uaa.WithNoAuthentication())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("LookupUserIDs()", func() {
+		it("calls the /ids/Users endpoint with the filter", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodGet))
+				Expect(req.URL.Path).To(Equal(uaa.UserIDsEndpoint))
+				Expect(req.URL.Query().Get("filter")).To(Equal(`userName eq "marcus"`))
+				Expect(req.URL.Query().Get("startIndex")).To(Equal("1"))
+				Expect(req.URL.Query().Get("count")).To(Equal("100"))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(uaa.UserIDEntry{ID: "user-id-1", Username: "marcus", Origin: "uaa"})))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			entries, err := a.LookupUserIDs(`userName eq "marcus"`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(ConsistOf(uaa.UserIDEntry{ID: "user-id-1", Username: "marcus", Origin: "uaa"}))
+			Expect(called).To(Equal(1))
+		})
+
+		it("follows pagination", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				var response string
+				switch req.URL.Query().Get("startIndex") {
+				case "1":
+					response = MultiPaginatedResponse(1, 1, 2, uaa.UserIDEntry{ID: "user-id-1"})
+				case "2":
+					response = MultiPaginatedResponse(2, 1, 2, uaa.UserIDEntry{ID: "user-id-2"})
+				}
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(response))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			entries, err := a.LookupUserIDs("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(called).To(Equal(2))
+		})
+
+		it("returns an error when the request fails", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			})
+			_, err := a.LookupUserIDs("")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	when("ResolveUsernames()", func() {
+		it("combines the IDs into an OR-filter and returns a map", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get("filter")).To(Equal(`id eq "user-id-1" or id eq "user-id-2"`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(
+					uaa.UserIDEntry{ID: "user-id-1", Username: "marcus"},
+					uaa.UserIDEntry{ID: "user-id-2", Username: "drseuss"},
+				)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			names, err := a.ResolveUsernames([]string{"user-id-1", "user-id-2", "user-id-1", ""})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal(map[string]string{"user-id-1": "marcus", "user-id-2": "drseuss"}))
+			Expect(called).To(Equal(1))
+		})
+
+		it("chunks large lookups into several requests", func() {
+			var ids []string
+			for i := 0; i < 120; i++ {
+				ids = append(ids, fmt.Sprintf("user-id-%d", i))
+			}
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(strings.Count(req.URL.Query().Get("filter"), " eq ")).To(BeNumerically("<=", 50))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse()))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			names, err := a.ResolveUsernames(ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(BeEmpty())
+			Expect(called).To(Equal(3))
+		})
+
+		it("does not make a request when there is nothing to resolve", func() {
+			names, err := a.ResolveUsernames(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(BeEmpty())
+			Expect(called).To(Equal(0))
+		})
+	})
+
+	when("ResolveUserIDs()", func() {
+		it("restricts the lookup to the origin", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get("filter")).To(Equal(`(userName eq "marcus" or userName eq "drseuss") and origin eq "ldap"`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(
+					uaa.UserIDEntry{ID: "user-id-1", Username: "marcus", Origin: "ldap"},
+				)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			ids, err := a.ResolveUserIDs([]string{"marcus", "drseuss"}, "ldap")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(Equal(map[string]string{"marcus": "user-id-1"}))
+		})
+
+		it("returns an error when a username matches users in more than one origin", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Query().Get("filter")).To(Equal(`userName eq "marcus" or userName eq "drseuss"`))
+				w.WriteHeader(http.StatusOK)
+				_, err := w.Write([]byte(PaginatedResponse(
+					uaa.UserIDEntry{ID: "user-id-1", Username: "marcus", Origin: "uaa"},
+					uaa.UserIDEntry{ID: "user-id-2", Username: "marcus", Origin: "ldap"},
+					uaa.UserIDEntry{ID: "user-id-3", Username: "drseuss", Origin: "uaa"},
+				)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+			ids, err := a.ResolveUserIDs([]string{"marcus", "drseuss"}, "")
+			Expect(err).To(MatchError(ContainSubstring("marcus (origins ldap, uaa)")))
+			Expect(err).NotTo(MatchError(ContainSubstring("drseuss")))
+			Expect(ids).To(BeNil())
+		})
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+
+	"github.com/cloudfoundry-community/go-uaa/scim"
 )
 
 // UsersEndpoint is the path to the users resource.
@@ -18,18 +20,24 @@ type Meta struct {
 	Version      int    `json:"version,omitempty"`
 	Created      string `json:"created,omitempty"`
 	LastModified string `json:"lastModified,omitempty"`
+	// Extra holds the members UAA returned that Meta does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // UserName is a person's name.
 type UserName struct {
 	FamilyName string `json:"familyName,omitempty"`
 	GivenName  string `json:"givenName,omitempty"`
+	// Extra holds the members UAA returned that UserName does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Email is an email address.
 type Email struct {
 	Value   string `json:"value,omitempty"`
 	Primary *bool  `json:"primary,omitempty"`
+	// Extra holds the members UAA returned that Email does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // UserGroup is a group that a user belongs to.
@@ -37,6 +45,8 @@ type UserGroup struct {
 	Value   string `json:"value,omitempty"`
 	Display string `json:"display,omitempty"`
 	Type    string `json:"type,omitempty"`
+	// Extra holds the members UAA returned that UserGroup does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Approval is a record of the user's explicit approval or rejection for an
@@ -48,11 +58,75 @@ type Approval struct {
 	Status        string `json:"status,omitempty"`
 	LastUpdatedAt string `json:"lastUpdatedAt,omitempty"`
 	ExpiresAt     string `json:"expiresAt,omitempty"`
+	// Extra holds the members UAA returned that Approval does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // PhoneNumber is a phone number for a user.
 type PhoneNumber struct {
 	Value string `json:"value"`
+	// Extra holds the members UAA returned that PhoneNumber does not model.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON encodes the Meta, including its Extra members.
+func (m Meta) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(m)
+}
+
+// UnmarshalJSON decodes the Meta, keeping unmodelled members in Extra.
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, m)
+}
+
+// MarshalJSON encodes the UserName, including its Extra members.
+func (n UserName) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(n)
+}
+
+// UnmarshalJSON decodes the UserName, keeping unmodelled members in Extra.
+func (n *UserName) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, n)
+}
+
+// MarshalJSON encodes the Email, including its Extra members.
+func (e Email) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(e)
+}
+
+// UnmarshalJSON decodes the Email, keeping unmodelled members in Extra.
+func (e *Email) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, e)
+}
+
+// MarshalJSON encodes the UserGroup, including its Extra members.
+func (g UserGroup) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(g)
+}
+
+// UnmarshalJSON decodes the UserGroup, keeping unmodelled members in Extra.
+func (g *UserGroup) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, g)
+}
+
+// MarshalJSON encodes the Approval, including its Extra members.
+func (a Approval) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(a)
+}
+
+// UnmarshalJSON decodes the Approval, keeping unmodelled members in Extra.
+func (a *Approval) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, a)
+}
+
+// MarshalJSON encodes the PhoneNumber, including its Extra members.
+func (p PhoneNumber) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(p)
+}
+
+// UnmarshalJSON decodes the PhoneNumber, keeping unmodelled members in Extra.
+func (p *PhoneNumber) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, p)
 }
 
 // User is a UAA user
@@ -76,6 +150,8 @@ type User struct {
 	PreviousLogonTime    int           `json:"previousLogonTime,omitempty"`
 	LastLogonTime        int           `json:"lastLogonTime,omitempty"`
 	Schemas              []string      `json:"schemas,omitempty"`
+	// Extra holds the members UAA returned that User does not model.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Identifier returns the field used to uniquely identify a User.
@@ -97,15 +173,15 @@ func (a *API) GetUserByUsername(username, origin, attributes string) (*User, err
 		return nil, errors.New("username cannot be blank")
 	}
 
-	filter := fmt.Sprintf(`userName eq "%v"`, username)
+	filter := scim.Eq("userName", username)
 	help := fmt.Sprintf("user %v not found", username)
 
 	if origin != "" {
-		filter = fmt.Sprintf(`%s and origin eq "%v"`, filter, origin)
+		filter = scim.And(filter, scim.Eq("origin", origin))
 		help = fmt.Sprintf(`%s in origin %v`, help, origin)
 	}
 
-	users, err := a.ListAllUsers(filter, "", attributes, "")
+	users, err := a.ListAllUsers(filter.String(), "", attributes, "")
 	if err != nil {
 		return nil, err
 	}
@@ -152,3 +228,44 @@ func (a *API) setActive(active bool, userID string, userMetaVersion int) error {
 	}
 	return a.doJSONWithHeaders(http.MethodPatch, &u, extraHeaders, bytes.NewBuffer([]byte(j)), nil, true)
 }
+
+// ResetUserMFA deletes the MFA registration for the user with the given user
+// ID, so that the user can register a new device on their next login
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#delete-a-user-s-mfa-registration.
+func (a *API) ResetUserMFA(userID string) error {
+	if userID == "" {
+		return errors.New("userID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("%s/%s/mfa", UsersEndpoint, userID))
+	return a.doJSON(http.MethodDelete, &u, nil, nil, true)
+}
+
+// RevokeUserTokens revokes all tokens issued to the user with the given user
+// ID
+// http://docs.cloudfoundry.org/api/uaa/version/4.19.0/index.html#revoke-all-tokens-for-a-user.
+func (a *API) RevokeUserTokens(userID string) error {
+	if userID == "" {
+		return errors.New("userID cannot be blank")
+	}
+	u := urlWithPath(*a.TargetURL, fmt.Sprintf("/oauth/token/revoke/user/%s", userID))
+	return a.doJSON(http.MethodGet, &u, nil, nil, true)
+}
+
+// ResetUserMFAByUsername resolves the user with the given username and origin,
+// resets their MFA registration and, if revokeTokens is true, revokes all of
+// their tokens. It returns the user whose registration was reset.
+func (a *API) ResetUserMFAByUsername(username, origin string, revokeTokens bool) (*User, error) {
+	user, err := a.GetUserByUsername(username, origin, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := a.ResetUserMFA(user.ID); err != nil {
+		return nil, err
+	}
+	if revokeTokens {
+		if err := a.RevokeUserTokens(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
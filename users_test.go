@@ -186,6 +186,18 @@ func testUsers(t *testing.T, when spec.G, it spec.S) {
 		})
 
 		when("no origin is specified", func() {
+			it("escapes quotes in the username", func() {
+				handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					Expect(req.URL.Query().Get("filter")).To(Equal(`userName eq "mar\"cus"`))
+					w.WriteHeader(http.StatusOK)
+					_, err := w.Write([]byte(PaginatedResponse(uaa.User{Username: `mar"cus`})))
+					Expect(err).NotTo(HaveOccurred())
+				})
+				u, err := a.GetUserByUsername(`mar"cus`, "", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(u.Username).To(Equal(`mar"cus`))
+			})
+
 			it("looks up a user with a SCIM filter", func() {
 				user := uaa.User{Username: "marcus", Origin: "uaa"}
 				response := PaginatedResponse(user)
@@ -522,6 +534,75 @@ func testUsers(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("ResetUserMFA()", func() {
+		it("returns an error when the userID is empty", func() {
+			err := a.ResetUserMFA("")
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(Equal(0))
+		})
+
+		it("deletes the user's MFA registration", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Accept")).To(Equal("application/json"))
+				Expect(req.Method).To(Equal(http.MethodDelete))
+				Expect(req.URL.Path).To(Equal("/Users/fb5f32e1-5cb3-49e6-93df-6df9c8c8bd70/mfa"))
+				w.WriteHeader(http.StatusOK)
+			})
+			err := a.ResetUserMFA("fb5f32e1-5cb3-49e6-93df-6df9c8c8bd70")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(called).To(Equal(1))
+		})
+
+		it("returns a helpful error the request fails", func() {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+			err := a.ResetUserMFA("fb5f32e1-5cb3-49e6-93df-6df9c8c8bd70")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("An error occurred while calling"))
+			Expect(called).To(Equal(1))
+		})
+	})
+
+	when("ResetUserMFAByUsername()", func() {
+		var paths []string
+
+		it.Before(func() {
+			paths = nil
+			handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				paths = append(paths, req.Method+" "+req.URL.Path)
+				if req.URL.Path == "/Users" {
+					Expect(req.URL.Query().Get("filter")).To(Equal(`userName eq "marcus" and origin eq "uaa"`))
+					w.WriteHeader(http.StatusOK)
+					_, err := w.Write([]byte(PaginatedResponse(uaa.User{ID: "user-id-1", Username: "marcus", Origin: "uaa"})))
+					Expect(err).NotTo(HaveOccurred())
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+		})
+
+		it("resolves the user and resets their MFA registration", func() {
+			user, err := a.ResetUserMFAByUsername("marcus", "uaa", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user.ID).To(Equal("user-id-1"))
+			Expect(paths).To(Equal([]string{
+				"GET /Users",
+				"DELETE /Users/user-id-1/mfa",
+			}))
+		})
+
+		it("revokes the user's tokens when asked to", func() {
+			_, err := a.ResetUserMFAByUsername("marcus", "uaa", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(paths).To(Equal([]string{
+				"GET /Users",
+				"DELETE /Users/user-id-1/mfa",
+				"GET /oauth/token/revoke/user/user-id-1",
+			}))
+		})
+	})
+
 	when("using user structs", func() {
 		when("verified", func() {
 			it("correctly shows false boolean values", func() {
@@ -610,5 +691,26 @@ func testUsers(t *testing.T, when spec.G, it spec.S) {
 				Expect(*newUser.Active).To(BeTrue())
 			})
 		})
+
+		when("nested values have members go-uaa does not model", func() {
+			it("encodes them again", func() {
+				userJSON := `{
+					"id": "user-1",
+					"meta": {"version": 1, "futureMetaField": true},
+					"name": {"givenName": "Marcus", "middleName": "Aurelius"},
+					"emails": [{"value": "marcus@example.com", "type": "work"}],
+					"groups": [{"value": "g1", "display": "uaa.user", "futureGroupField": 1}],
+					"approvals": [{"clientId": "cf", "scope": "openid", "futureApprovalField": "x"}],
+					"phoneNumbers": [{"value": "555-0100", "type": "mobile"}]
+				}`
+				var user uaa.User
+				Expect(json.Unmarshal([]byte(userJSON), &user)).To(Succeed())
+				Expect(user.Emails[0].Extra).To(HaveKeyWithValue("type", json.RawMessage(`"work"`)))
+				Expect(user.Name.Extra).To(HaveKey("middleName"))
+				j, err := json.Marshal(user)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(j).To(MatchJSON(userJSON))
+			})
+		})
 	})
 }
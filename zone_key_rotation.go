@@ -0,0 +1,381 @@
+package uaa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	defaultKeySize             = 2048
+	defaultKeyPublishTimeout   = 5 * time.Minute
+	defaultKeyPollInterval     = 5 * time.Second
+	defaultSAMLCertificateLife = 365 * 24 * time.Hour
+)
+
+// RotateZoneSigningKeyOptions controls RotateZoneSigningKey.
+type RotateZoneSigningKeyOptions struct {
+	// KeyID is the ID of the new key. It defaults to a timestamped ID.
+	KeyID string
+	// KeySize is the size in bits of the new RSA key. It defaults to 2048.
+	KeySize int
+	// CurrentKeys are the zone's existing signing keys. UAA does not return
+	// signing keys when a zone is read, so keys that should remain valid
+	// while the new key is rolled out must be supplied here. They must
+	// include the zone's active key, which would otherwise be deleted.
+	CurrentKeys map[string]TokenPolicyKey
+	// PublishTimeout bounds how long to wait for the new key to be published
+	// by the zone's token_keys endpoint. It defaults to five minutes.
+	PublishTimeout time.Duration
+	// PollInterval is how often the token_keys endpoint is checked. It
+	// defaults to five seconds.
+	PollInterval time.Duration
+	// GracePeriod is how long tokens signed by the old keys remain
+	// verifiable after the new key becomes active. When it is zero the old
+	// keys are left in place and are not retired.
+	GracePeriod time.Duration
+}
+
+// ZoneSigningKeyRotation is the result of RotateZoneSigningKey.
+type ZoneSigningKeyRotation struct {
+	KeyID string
+	// Key holds the new private key. UAA will not return it, so it should
+	// be stored securely if it will be needed for later rotations.
+	Key           TokenPolicyKey
+	RetiredKeyIDs []string
+}
+
+// RotateZoneSigningKey replaces the token signing key of the zone with the
+// given ID without invalidating tokens in flight. It generates a new RSA key,
+// adds it to the zone's token policy keys, waits until the zone's token_keys
+// endpoint publishes it so that resource servers can verify tokens signed by
+// it, and makes it the active key. After opts.GracePeriod, the old keys are
+// removed.
+//
+// The zone must already have signing keys of its own, because a zone's only
+// key is active as soon as it is added. RotateZoneSigningKey returns an error
+// if it does not, or if opts.CurrentKeys does not include the active key.
+func (a *API) RotateZoneSigningKey(ctx context.Context, zoneID string, opts RotateZoneSigningKeyOptions) (*ZoneSigningKeyRotation, error) {
+	if zoneID == "" {
+		return nil, errors.New("zoneID cannot be blank")
+	}
+	zone, err := a.GetIdentityZone(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	activeKeyID := ""
+	if zone.Config.TokenPolicy != nil {
+		activeKeyID = zone.Config.TokenPolicy.ActiveKeyID
+	}
+	if activeKeyID == "" && len(opts.CurrentKeys) == 1 {
+		for id := range opts.CurrentKeys {
+			activeKeyID = id
+		}
+	}
+	switch {
+	case activeKeyID == "" && len(opts.CurrentKeys) == 0:
+		return nil, fmt.Errorf("zone %v has no signing keys of its own, so a new key would be active before it is published", zoneID)
+	case activeKeyID == "":
+		return nil, fmt.Errorf("zone %v has several signing keys but no active key", zoneID)
+	}
+	if _, ok := opts.CurrentKeys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("CurrentKeys must include the active signing key %v of zone %v, because UAA does not return it and it would be deleted", activeKeyID, zoneID)
+	}
+
+	keys := map[string]TokenPolicyKey{}
+	for id, key := range opts.CurrentKeys {
+		keys[id] = key
+	}
+
+	keyID := opts.KeyID
+	if keyID == "" {
+		keyID = newKeyID()
+	}
+	if _, ok := keys[keyID]; ok {
+		return nil, fmt.Errorf("zone %v already has a signing key with ID %v", zoneID, keyID)
+	}
+	private, err := generateRSAKey(opts.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	rotation := &ZoneSigningKeyRotation{
+		KeyID: keyID,
+		Key:   TokenPolicyKey{SigningKey: string(encodeRSAPrivateKey(private))},
+	}
+	keys[keyID] = rotation.Key
+
+	withKeys := func(keys map[string]TokenPolicyKey, activeKeyID string) func(*IdentityZoneConfig) {
+		return func(config *IdentityZoneConfig) {
+			if config.TokenPolicy == nil {
+				config.TokenPolicy = &TokenPolicy{}
+			}
+			config.TokenPolicy.Keys = keys
+			if activeKeyID != "" {
+				config.TokenPolicy.ActiveKeyID = activeKeyID
+			}
+		}
+	}
+
+	// The current key stays active until the new key is published.
+	if _, err := a.updateZoneConfig(zoneID, withKeys(keys, activeKeyID)); err != nil {
+		return nil, err
+	}
+
+	if err := a.waitForTokenKey(ctx, zone.Subdomain, keyID, opts.PublishTimeout, opts.PollInterval); err != nil {
+		return rotation, err
+	}
+
+	if _, err := a.updateZoneConfig(zoneID, withKeys(keys, keyID)); err != nil {
+		return rotation, err
+	}
+
+	if opts.GracePeriod <= 0 {
+		return rotation, nil
+	}
+	if err := sleepContext(ctx, opts.GracePeriod); err != nil {
+		return rotation, err
+	}
+	for id := range keys {
+		if id != keyID {
+			rotation.RetiredKeyIDs = append(rotation.RetiredKeyIDs, id)
+		}
+	}
+	_, err = a.updateZoneConfig(zoneID, withKeys(map[string]TokenPolicyKey{keyID: rotation.Key}, keyID))
+	if err != nil {
+		rotation.RetiredKeyIDs = nil
+		return rotation, err
+	}
+	return rotation, nil
+}
+
+// RotateZoneSAMLKeyOptions controls RotateZoneSAMLKey.
+type RotateZoneSAMLKeyOptions struct {
+	// KeyID is the ID of the new key. It defaults to a timestamped ID.
+	KeyID string
+	// KeySize is the size in bits of the new RSA key. It defaults to 2048.
+	KeySize int
+	// Subject is the subject of the new self-signed certificate. Its
+	// common name defaults to the zone's subdomain.
+	Subject pkix.Name
+	// CertificateValidity is how long the new certificate is valid for. It
+	// defaults to one year.
+	CertificateValidity time.Duration
+	// ActivationDelay is how long the new certificate is published in the
+	// zone's metadata before the new key becomes active, so that service
+	// providers can pick it up.
+	ActivationDelay time.Duration
+	// GracePeriod is how long the old keys remain in the zone's metadata
+	// after the new key becomes active. When it is zero the old keys are
+	// left in place and are not retired.
+	GracePeriod time.Duration
+}
+
+// ZoneSAMLKeyRotation is the result of RotateZoneSAMLKey.
+type ZoneSAMLKeyRotation struct {
+	KeyID string
+	// Key holds the new private key and certificate. UAA will not return
+	// the private key, so it should be stored securely if needed later.
+	Key           SAMLKey
+	RetiredKeyIDs []string
+}
+
+// RotateZoneSAMLKey replaces the SAML signing key of the zone with the given
+// ID. It generates a new RSA key and self-signed certificate and adds them to
+// the zone's SAML keys. After opts.ActivationDelay it makes the new key
+// active, and after a further opts.GracePeriod the old keys are removed. A
+// zone without SAML keys has the new key made active immediately.
+func (a *API) RotateZoneSAMLKey(ctx context.Context, zoneID string, opts RotateZoneSAMLKeyOptions) (*ZoneSAMLKeyRotation, error) {
+	if zoneID == "" {
+		return nil, errors.New("zoneID cannot be blank")
+	}
+	zone, err := a.GetIdentityZone(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := opts.KeyID
+	if keyID == "" {
+		keyID = newKeyID()
+	}
+	if zone.Config.SAMLConfig != nil {
+		if _, ok := zone.Config.SAMLConfig.Keys[keyID]; ok {
+			return nil, fmt.Errorf("zone %v already has a SAML key with ID %v", zoneID, keyID)
+		}
+	}
+	subject := opts.Subject
+	if subject.CommonName == "" {
+		subject.CommonName = zone.Subdomain
+	}
+	validity := opts.CertificateValidity
+	if validity <= 0 {
+		validity = defaultSAMLCertificateLife
+	}
+	private, err := generateRSAKey(opts.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := selfSignedCertificate(private, subject, validity)
+	if err != nil {
+		return nil, err
+	}
+	rotation := &ZoneSAMLKeyRotation{
+		KeyID: keyID,
+		Key: SAMLKey{
+			Key:         string(encodeRSAPrivateKey(private)),
+			Certificate: string(certificate),
+		},
+	}
+
+	// UAA keeps the private keys of existing SAML keys that are sent back
+	// with only their certificates, so each update only needs to carry the
+	// new key's private key.
+	var oldKeyIDs []string
+	withKey := func(activate bool, retire bool) func(*IdentityZoneConfig) {
+		return func(config *IdentityZoneConfig) {
+			if config.SAMLConfig == nil {
+				config.SAMLConfig = &SAMLConfig{}
+			}
+			if config.SAMLConfig.Keys == nil || retire {
+				config.SAMLConfig.Keys = map[string]SAMLKey{}
+			}
+			config.SAMLConfig.Keys[keyID] = rotation.Key
+			if activate || config.SAMLConfig.ActiveKeyID == "" {
+				config.SAMLConfig.ActiveKeyID = keyID
+			}
+		}
+	}
+
+	updated, err := a.updateZoneConfig(zoneID, withKey(false, false))
+	if err != nil {
+		return nil, err
+	}
+	for id := range updated.Config.SAMLConfig.Keys {
+		if id != keyID {
+			oldKeyIDs = append(oldKeyIDs, id)
+		}
+	}
+	if len(oldKeyIDs) == 0 {
+		return rotation, nil
+	}
+
+	if err := sleepContext(ctx, opts.ActivationDelay); err != nil {
+		return rotation, err
+	}
+	if _, err := a.updateZoneConfig(zoneID, withKey(true, false)); err != nil {
+		return rotation, err
+	}
+
+	if opts.GracePeriod <= 0 {
+		return rotation, nil
+	}
+	if err := sleepContext(ctx, opts.GracePeriod); err != nil {
+		return rotation, err
+	}
+	if _, err := a.updateZoneConfig(zoneID, withKey(true, true)); err != nil {
+		return rotation, err
+	}
+	rotation.RetiredKeyIDs = oldKeyIDs
+	return rotation, nil
+}
+
+// updateZoneConfig reads the zone with the given ID, applies update to its
+// config and saves it.
+func (a *API) updateZoneConfig(zoneID string, update func(*IdentityZoneConfig)) (*IdentityZone, error) {
+	zone, err := a.GetIdentityZone(zoneID)
+	if err != nil {
+		return nil, err
+	}
+	update(&zone.Config)
+	return a.UpdateIdentityZone(*zone)
+}
+
+// waitForTokenKey polls the token_keys endpoint of the zone with the given
+// subdomain until it publishes the key with the given ID.
+func (a *API) waitForTokenKey(ctx context.Context, subdomain string, keyID string, timeout time.Duration, interval time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultKeyPublishTimeout
+	}
+	if interval <= 0 {
+		interval = defaultKeyPollInterval
+	}
+	zoned := a
+	if subdomain != "" {
+		var err error
+		if zoned, err = a.ForZoneSubdomain(subdomain); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		keys, err := zoned.TokenKeys()
+		if err == nil {
+			for _, key := range keys {
+				if key.Kid == keyID {
+					return nil
+				}
+			}
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return fmt.Errorf("waiting for signing key %v to be published: %v", keyID, err)
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func newKeyID() string {
+	return fmt.Sprintf("key-%d", time.Now().UTC().Unix())
+}
+
+func generateRSAKey(bits int) (*rsa.PrivateKey, error) {
+	if bits <= 0 {
+		bits = defaultKeySize
+	}
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
+func encodeRSAPrivateKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+}
+
+func selfSignedCertificate(key *rsa.PrivateKey, subject pkix.Name, validity time.Duration) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
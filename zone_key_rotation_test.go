@@ -0,0 +1,261 @@
+package uaa_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	uaa "github.com/cloudfoundry-community/go-uaa"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+// hostRewritingTransport sends every request to the given server while
+// keeping the original Host header, so that zone subdomains can be tested.
+type hostRewritingTransport struct {
+	target *url.URL
+}
+
+func (t *hostRewritingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Host = req.URL.Host
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func testZoneKeyRotation(t *testing.T, when spec.G, it spec.S) {
+	var (
+		s           *httptest.Server
+		a           *uaa.API
+		mu          sync.Mutex
+		zone        uaa.IdentityZone
+		samlKeys    map[string]string
+		updates     []uaa.IdentityZoneConfig
+		published   []string
+		publishedAt int
+		polls       int
+		tokenHosts  []string
+	)
+
+	it.Before(func() {
+		RegisterTestingT(t)
+		zone = uaa.IdentityZone{ID: "tenant", Subdomain: "tenant", Name: "tenant"}
+		samlKeys = map[string]string{}
+		updates = nil
+		published = []string{"old-key"}
+		publishedAt = 2
+		polls = 0
+		tokenHosts = nil
+
+		s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case req.Method == http.MethodGet && req.URL.Path == "/identity-zones/tenant":
+				// UAA does not return token signing keys or SAML private keys.
+				var read uaa.IdentityZone
+				j, _ := json.Marshal(zone)
+				Expect(json.Unmarshal(j, &read)).To(Succeed())
+				if read.Config.TokenPolicy != nil {
+					read.Config.TokenPolicy.Keys = nil
+				}
+				if read.Config.SAMLConfig != nil {
+					for id, key := range read.Config.SAMLConfig.Keys {
+						key.Key = ""
+						read.Config.SAMLConfig.Keys[id] = key
+					}
+				}
+				j, _ = json.Marshal(read)
+				_, err := w.Write(j)
+				Expect(err).NotTo(HaveOccurred())
+			case req.Method == http.MethodPut && req.URL.Path == "/identity-zones/tenant":
+				body, _ := ioutil.ReadAll(req.Body)
+				var updated uaa.IdentityZone
+				Expect(json.Unmarshal(body, &updated)).To(Succeed())
+				updates = append(updates, updated.Config)
+				if saml := updated.Config.SAMLConfig; saml != nil {
+					for id, key := range saml.Keys {
+						if key.Key == "" {
+							key.Key = samlKeys[id]
+							saml.Keys[id] = key
+						}
+						samlKeys[id] = key.Key
+					}
+				}
+				zone = updated
+				_, err := w.Write(body)
+				Expect(err).NotTo(HaveOccurred())
+			case req.Method == http.MethodGet && req.URL.Path == "/token_keys":
+				tokenHosts = append(tokenHosts, req.Host)
+				polls++
+				keys := uaa.Keys{}
+				for _, kid := range published {
+					keys.Keys = append(keys.Keys, uaa.JWK{Kid: kid, Kty: "RSA"})
+				}
+				if polls >= publishedAt && zone.Config.TokenPolicy != nil {
+					for kid := range zone.Config.TokenPolicy.Keys {
+						if kid != "old-key" {
+							keys.Keys = append(keys.Keys, uaa.JWK{Kid: kid, Kty: "RSA"})
+						}
+					}
+				}
+				j, _ := json.Marshal(keys)
+				_, err := w.Write(j)
+				Expect(err).NotTo(HaveOccurred())
+			default:
+				t.Errorf("unexpected request %v %v", req.Method, req.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		target, _ := url.Parse(s.URL)
+		var err error
+		a, err = uaa.New("http://login.example.com", uaa.WithNoAuthentication(), uaa.WithClient(&http.Client{Transport: &hostRewritingTransport{target: target}}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		if s != nil {
+			s.Close()
+		}
+	})
+
+	when("RotateZoneSigningKey()", func() {
+		it.Before(func() {
+			zone.Config.TokenPolicy = &uaa.TokenPolicy{
+				ActiveKeyID: "old-key",
+				Keys:        map[string]uaa.TokenPolicyKey{"old-key": {SigningKey: "old-pem"}},
+			}
+		})
+
+		it("adds the key, waits for it to be published, activates it and retires the old key", func() {
+			rotation, err := a.RotateZoneSigningKey(context.Background(), "tenant", uaa.RotateZoneSigningKeyOptions{
+				KeyID:        "new-key",
+				KeySize:      1024,
+				CurrentKeys:  map[string]uaa.TokenPolicyKey{"old-key": {SigningKey: "old-pem"}},
+				PollInterval: time.Millisecond,
+				GracePeriod:  time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotation.KeyID).To(Equal("new-key"))
+			Expect(rotation.RetiredKeyIDs).To(Equal([]string{"old-key"}))
+
+			block, _ := pem.Decode([]byte(rotation.Key.SigningKey))
+			Expect(block).NotTo(BeNil())
+			_, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(updates).To(HaveLen(3))
+			Expect(updates[0].TokenPolicy.ActiveKeyID).To(Equal("old-key"))
+			Expect(updates[0].TokenPolicy.Keys).To(HaveKey("old-key"))
+			Expect(updates[0].TokenPolicy.Keys).To(HaveKey("new-key"))
+			Expect(updates[1].TokenPolicy.ActiveKeyID).To(Equal("new-key"))
+			Expect(updates[1].TokenPolicy.Keys).To(HaveLen(2))
+			Expect(updates[2].TokenPolicy.ActiveKeyID).To(Equal("new-key"))
+			Expect(updates[2].TokenPolicy.Keys).To(HaveLen(1))
+			Expect(updates[2].TokenPolicy.Keys).To(HaveKey("new-key"))
+
+			Expect(polls).To(Equal(2))
+			Expect(tokenHosts).To(HaveEach("tenant.login.example.com"))
+		})
+
+		it("leaves the old key in place without a grace period", func() {
+			rotation, err := a.RotateZoneSigningKey(context.Background(), "tenant", uaa.RotateZoneSigningKeyOptions{
+				KeyID:        "new-key",
+				KeySize:      1024,
+				CurrentKeys:  map[string]uaa.TokenPolicyKey{"old-key": {SigningKey: "old-pem"}},
+				PollInterval: time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotation.RetiredKeyIDs).To(BeEmpty())
+			Expect(updates).To(HaveLen(2))
+			Expect(zone.Config.TokenPolicy.Keys).To(HaveLen(2))
+		})
+
+		it("does not activate a key that is never published", func() {
+			publishedAt = 1000
+			_, err := a.RotateZoneSigningKey(context.Background(), "tenant", uaa.RotateZoneSigningKeyOptions{
+				KeyID:          "new-key",
+				KeySize:        1024,
+				CurrentKeys:    map[string]uaa.TokenPolicyKey{"old-key": {SigningKey: "old-pem"}},
+				PublishTimeout: 20 * time.Millisecond,
+				PollInterval:   time.Millisecond,
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("new-key"))
+			Expect(updates).To(HaveLen(1))
+			Expect(zone.Config.TokenPolicy.ActiveKeyID).To(Equal("old-key"))
+		})
+
+		it("does not delete the active key when it is not among the current keys", func() {
+			_, err := a.RotateZoneSigningKey(context.Background(), "tenant", uaa.RotateZoneSigningKeyOptions{
+				KeyID:        "new-key",
+				KeySize:      1024,
+				PollInterval: time.Millisecond,
+			})
+			Expect(err).To(MatchError("CurrentKeys must include the active signing key old-key of zone tenant, because UAA does not return it and it would be deleted"))
+			Expect(updates).To(BeEmpty())
+		})
+
+		it("does not add a key to a zone without signing keys", func() {
+			zone.Config.TokenPolicy = nil
+			_, err := a.RotateZoneSigningKey(context.Background(), "tenant", uaa.RotateZoneSigningKeyOptions{
+				KeyID:        "new-key",
+				KeySize:      1024,
+				PollInterval: time.Millisecond,
+			})
+			Expect(err).To(MatchError("zone tenant has no signing keys of its own, so a new key would be active before it is published"))
+			Expect(updates).To(BeEmpty())
+		})
+	})
+
+	when("RotateZoneSAMLKey()", func() {
+		it.Before(func() {
+			samlKeys["old-key"] = "old-private-key"
+			zone.Config.SAMLConfig = &uaa.SAMLConfig{
+				ActiveKeyID: "old-key",
+				Keys:        map[string]uaa.SAMLKey{"old-key": {Key: "old-private-key", Certificate: "old-cert"}},
+			}
+		})
+
+		it("adds the key and certificate, activates it and retires the old key", func() {
+			rotation, err := a.RotateZoneSAMLKey(context.Background(), "tenant", uaa.RotateZoneSAMLKeyOptions{
+				KeyID:       "new-key",
+				KeySize:     1024,
+				GracePeriod: time.Millisecond,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotation.RetiredKeyIDs).To(Equal([]string{"old-key"}))
+
+			block, _ := pem.Decode([]byte(rotation.Key.Certificate))
+			Expect(block).NotTo(BeNil())
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.Subject.CommonName).To(Equal("tenant"))
+
+			Expect(updates).To(HaveLen(3))
+			Expect(updates[0].SAMLConfig.ActiveKeyID).To(Equal("old-key"))
+			Expect(updates[0].SAMLConfig.Keys).To(HaveLen(2))
+			Expect(updates[1].SAMLConfig.ActiveKeyID).To(Equal("new-key"))
+			Expect(updates[2].SAMLConfig.Keys).To(HaveLen(1))
+			Expect(zone.Config.SAMLConfig.Keys["new-key"].Key).To(Equal(rotation.Key.Key))
+		})
+
+		it("activates the key immediately when the zone has no SAML keys", func() {
+			zone.Config.SAMLConfig = nil
+			rotation, err := a.RotateZoneSAMLKey(context.Background(), "tenant", uaa.RotateZoneSAMLKeyOptions{KeyID: "new-key", KeySize: 1024})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotation.RetiredKeyIDs).To(BeEmpty())
+			Expect(updates).To(HaveLen(1))
+			Expect(zone.Config.SAMLConfig.ActiveKeyID).To(Equal("new-key"))
+		})
+	})
+}